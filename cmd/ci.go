@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/adipras/lupettogo/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var ciForce bool
+
+var ciCmd = &cobra.Command{
+	Use:   "ci [github|gitlab]",
+	Short: "Generate a CI pipeline for the project",
+	Long: `Generate a CI pipeline that builds the project, runs go vet and
+golangci-lint (configured by a generated .golangci.yml), runs the tests
+against a database service container matching the project's driver,
+uploads coverage and builds the Docker image when Docker is enabled.
+
+Examples:
+  lupettogo generate ci github
+  lupettogo generate ci gitlab --force`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: generator.CIProviders,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generator.GenerateCI(args[0], ciForce)
+	},
+}
+
+func init() {
+	ciCmd.Flags().BoolVar(&ciForce, "force", false, "Overwrite existing CI files")
+
+	generateCmd.AddCommand(ciCmd)
+}
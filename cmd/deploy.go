@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/adipras/lupettogo/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deployHelm  bool
+	deployForce bool
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy [k8s]",
+	Short: "Generate Kubernetes manifests or a Helm chart",
+	Long: `Generate a Deployment, Service, ConfigMap, Secret and HorizontalPodAutoscaler
+for the project, plus a worker Deployment when it has background jobs. The
+probes check /health and the settings are passed as the environment variables
+the generated config reads. Plain manifests with a kustomization go in
+deploy/k8s; with --helm a chart goes in deploy/helm instead. When the project
+has tests enabled, they validate the manifests against the Kubernetes API
+schemas offline.
+
+Examples:
+  lupettogo generate deploy k8s
+  lupettogo generate deploy k8s --helm
+  lupettogo generate deploy k8s --force`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: generator.DeployTargets,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generator.GenerateDeploy(args[0], deployHelm, deployForce)
+	},
+}
+
+func init() {
+	deployCmd.Flags().BoolVar(&deployHelm, "helm", false, "Generate a Helm chart instead of plain manifests")
+	deployCmd.Flags().BoolVar(&deployForce, "force", false, "Overwrite existing deploy files")
+
+	generateCmd.AddCommand(deployCmd)
+}
@@ -1,75 +1,136 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
-	"os/exec"
-	"regexp"
-	"strconv"
+	"os"
 	"strings"
 
+	"github.com/adipras/lupettogo/internal/doctor"
+	"github.com/adipras/lupettogo/internal/output"
 	"github.com/spf13/cobra"
 )
 
+var (
+	doctorStrict bool
+	doctorFix    bool
+	doctorYes    bool
+	doctorPacks  bool
+)
+
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Check your development environment for LupettoGo",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("🔍 Running environment check...")
+	Long: `Check the tools LupettoGo and generated projects need.
 
-		check("Go", "go", "version", "go version", validateGoVersion)
+Tools are checked against the versions generated projects need. Run inside
+a generated project, doctor also checks that go.mod matches lupettogo.yaml,
+that .env exists with every key from .env.example, that JWT_SECRET is set to
+a strong value, that the configured database is reachable, that the server
+port is free and that the project builds.
 
-		check("Git", "git", "--version", "git version", nil)
+The checks a template pack declares run the commands it names, and their
+fixes run its install commands. They only run with --pack-checks, so running
+doctor in a checkout you don't trust never runs code from its pack.
 
-		check("PostgreSQL (default)", "psql", "--version", "psql", nil)
+With --fix, doctor offers the remediation of each failing check that has one
+(creating .env, generating JWT_SECRET, running go mod tidy, installing
+golangci-lint), asks before applying it unless --yes is given, and runs the
+checks again to report their status before and after.
 
-		check("MySQL (optional)", "mysql", "--version", "mysql", nil)
+Exit codes:
+  0  all required checks passed
+  1  a required check failed
+  2  only warnings, reported with --strict`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var checks []doctor.Check
+		var results []doctor.Result
+		for _, group := range doctor.Groups(".", doctorPacks) {
+			if len(group.Checks) == 0 {
+				continue
+			}
+			output.Printf("🔍 Checking %s...\n", group.Title)
+			for _, result := range doctor.Run(group.Checks) {
+				printResult(result)
+				results = append(results, result)
+			}
+			checks = append(checks, group.Checks...)
+		}
 
-		check("Docker (optional)", "docker", "--version", "docker", nil)
+		if doctorFix {
+			var reports []doctor.FixReport
+			reports, results = doctor.FixAll(checks, results, confirmFix)
+			if len(reports) == 0 {
+				output.Println("✨ Nothing to fix")
+			} else {
+				printFixReports(reports)
+				output.Set("fixes", reports)
+			}
+		}
+		output.Set("checks", results)
 
-		fmt.Println("✅ Templates embedded in binary")
+		switch doctor.ExitCode(results, doctorStrict) {
+		case doctor.ExitFailed:
+			return &exitError{code: doctor.ExitFailed, err: fmt.Errorf("doctor found problems")}
+		case doctor.ExitWarnings:
+			return &exitError{code: doctor.ExitWarnings, err: fmt.Errorf("doctor found warnings")}
+		}
+		return nil
 	},
 }
 
-func check(name, bin, arg, expect string, validator func(string) bool) {
-	cmd := exec.Command(bin, arg)
-	out, err := cmd.CombinedOutput()
-	outStr := strings.TrimSpace(string(out))
+var statusIcons = map[string]string{
+	doctor.StatusOK:      "✅",
+	doctor.StatusSkipped: "⏭️ ",
+	doctor.StatusWarning: "⚠️ ",
+	doctor.StatusError:   "❌",
+}
 
-	if err != nil {
-		if strings.Contains(name, "optional") {
-			fmt.Printf("⚠️  %s not found (optional)\n", name)
-		} else {
-			fmt.Printf("❌ %s not found - please install %s\n", name, bin)
-		}
-		return
+func printResult(result doctor.Result) {
+	output.Printf("%s %s: %s\n", statusIcons[result.Status], result.Name, strings.ReplaceAll(result.Message, "\n", "\n   "))
+	if result.Fix != nil && result.Status != doctor.StatusOK && !doctorFix {
+		output.Printf("   💡 Fix: %s (lupettogo doctor --fix)\n", result.Fix.Description)
 	}
+}
 
-	if validator != nil && !validator(outStr) {
-		fmt.Printf("⚠️  %s found but version may not be compatible: %s\n", name, outStr)
-		fmt.Printf("   Required: Go 1.18 or higher\n")
-		return
+// confirmFix asks whether to apply a fix. Without a terminal to ask on,
+// fixes are only applied with --yes.
+func confirmFix(result doctor.Result) bool {
+	if doctorYes {
+		return true
+	}
+	if !output.Interactive() {
+		output.Warnf("Not fixing %s without confirmation, pass --yes to apply: %s", result.Name, result.Fix.Description)
+		return false
 	}
 
-	fmt.Printf("✅ %s OK: %s\n", name, outStr)
+	fmt.Printf("🔧 %s: %s? [y/N] ", result.Name, result.Fix.Description)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
 
-func validateGoVersion(output string) bool {
-	re := regexp.MustCompile(`go(\d+)\.(\d+)`)
-	matches := re.FindStringSubmatch(output)
-	if len(matches) < 3 {
-		return false
-	}
-	
-	major, err1 := strconv.Atoi(matches[1])
-	minor, err2 := strconv.Atoi(matches[2])
-	
-	if err1 != nil || err2 != nil {
-		return false
+func printFixReports(reports []doctor.FixReport) {
+	output.Println("🔧 Fixes:")
+	for _, report := range reports {
+		switch {
+		case report.Error != "":
+			output.Printf("   ❌ %s: %s failed: %s\n", report.Check, report.Fix, report.Error)
+		case !report.Applied:
+			output.Printf("   ⏭️  %s: skipped (%s)\n", report.Check, report.Before)
+		default:
+			output.Printf("   %s %s: %s → %s\n", statusIcons[report.After], report.Check, report.Before, report.After)
+		}
 	}
-	
-	return major > 1 || (major == 1 && minor >= 18)
 }
 
 func init() {
+	doctorCmd.Flags().BoolVar(&doctorStrict, "strict", false, "Exit with code 2 when any check warns")
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Offer to apply the remediation of failing checks")
+	doctorCmd.Flags().BoolVarP(&doctorYes, "yes", "y", false, "Apply fixes without asking")
+	doctorCmd.Flags().BoolVar(&doctorPacks, "pack-checks", false, "Run the checks declared by the project's template pack")
+
 	rootCmd.AddCommand(doctorCmd)
 }
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate code inside an existing LupettoGo project",
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+}
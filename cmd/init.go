@@ -1,17 +1,32 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/adipras/lupettogo/internal/generator"
+	"github.com/adipras/lupettogo/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
-	dbDriver   string
-	withAuth   bool
-	withDocker bool
-	withTests  bool
+	dbDriver        string
+	withAuth        bool
+	withDocker      bool
+	withTests       bool
+	withRedis       bool
+	withRateLimit   bool
+	withMetrics     bool
+	withTracing     bool
+	withJobs        bool
+	ciProvider      string
+	templateSource  string
+	templateOptions map[string]string
+	refreshTemplate bool
+	hookOptions     generator.HookOptions
+	noPackHooks     bool
 )
 
 var initCmd = &cobra.Command{
@@ -26,13 +41,30 @@ The project includes:
 - HTTP server with Gin
 - Middleware support (CORS, logging, recovery)
 - Environment configuration
-- Docker support (optional)
+- Docker support with docker-compose for the database (optional)
 - Testing infrastructure (optional)
 
+A template pack can be layered over the built-in templates with --template,
+either a git repository (git+<url>[@ref]) or a local directory. Packs are
+cached under the user config directory; values for the options a pack
+declares are prompted for, or passed with --set.
+
+Once the files are written, --tidy runs 'go mod tidy', --vendor runs
+'go mod vendor', the pack's hooks run, and --git creates a repository with
+an initial commit. --offline skips the steps that need the network.
+
+A pack's hooks run shell commands from wherever the pack came from, so they
+are listed and only run once you confirm them, or with --pack-hooks when
+there is no terminal to ask on. --no-hooks skips them without asking.
+
 Examples:
   lupettogo init my-saas-app
   lupettogo init my-api --db postgres --with-auth --with-docker
-  lupettogo init simple-api --db mysql --with-tests`,
+  lupettogo init simple-api --db mysql --with-tests
+  lupettogo init my-api --template git+https://github.com/acme/acme-starter@v2 --set team=payments
+  lupettogo init my-api --template ./acme-starter
+  lupettogo init my-api --git --tidy
+  lupettogo init my-api --ci github`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		projectName := args[0]
@@ -42,26 +74,100 @@ Examples:
 			return err
 		}
 
+		if err := generator.ValidateDBDriver(dbDriver); err != nil {
+			return err
+		}
+		if ciProvider != "" {
+			if err := generator.ValidateCIProvider(ciProvider); err != nil {
+				return err
+			}
+		}
+
 		// Show configuration
-		fmt.Printf("🐺 Creating project '%s' with:\n", projectName)
-		fmt.Printf("   Database: %s\n", dbDriver)
-		fmt.Printf("   Auth: %v\n", withAuth)
-		fmt.Printf("   Docker: %v\n", withDocker)
-		fmt.Printf("   Tests: %v\n", withTests)
-		fmt.Println()
+		output.Printf("🐺 Creating project '%s' with:\n", projectName)
+		output.Printf("   Database: %s\n", dbDriver)
+		output.Printf("   Auth: %v\n", withAuth)
+		output.Printf("   Docker: %v\n", withDocker)
+		output.Printf("   Tests: %v\n", withTests)
+		if withRedis {
+			output.Printf("   Redis: %v\n", withRedis)
+		}
+		if withRateLimit {
+			output.Printf("   Rate limit: %v\n", withRateLimit)
+		}
+		if withMetrics {
+			output.Printf("   Metrics: %v\n", withMetrics)
+		}
+		if withTracing {
+			output.Printf("   Tracing: %v\n", withTracing)
+		}
+		if withJobs {
+			output.Printf("   Jobs: %v\n", withJobs)
+		}
+		if ciProvider != "" {
+			output.Printf("   CI: %s\n", ciProvider)
+		}
+		output.Println()
 
 		config := generator.ProjectConfig{
-			Name:       projectName,
-			DBDriver:   dbDriver,
-			WithAuth:   withAuth,
-			WithDocker: withDocker,
-			WithTests:  withTests,
+			Name:          projectName,
+			DBDriver:      dbDriver,
+			WithAuth:      withAuth,
+			WithDocker:    withDocker,
+			WithTests:     withTests,
+			WithRedis:     withRedis,
+			WithRateLimit: withRateLimit,
+			WithMetrics:   withMetrics,
+			WithTracing:   withTracing,
+			WithJobs:      withJobs,
+			CI:            ciProvider,
+			Hooks:         hookOptions,
+		}
+
+		if templateSource != "" {
+			pack, err := generator.LoadTemplatePack(templateSource, refreshTemplate)
+			if err != nil {
+				return err
+			}
+			output.Printf("📦 Using template pack '%s'\n", pack.Name)
+
+			options, err := generator.ResolvePackOptions(pack, templateOptions, output.Interactive())
+			if err != nil {
+				return err
+			}
+			config.Pack = pack
+			config.Options = options
+
+			switch {
+			case noPackHooks:
+				config.Hooks.PackHooks = false
+			case len(pack.Hooks) > 0 && !hookOptions.PackHooks:
+				config.Hooks.PackHooks = confirmPackHooks(pack)
+			}
 		}
 
 		return generator.GenerateProjectWithConfig(config)
 	},
 }
 
+// confirmPackHooks lists the commands the pack's hooks run and asks whether
+// to run them. Without a terminal to ask on, they only run with --pack-hooks.
+func confirmPackHooks(pack *generator.TemplatePack) bool {
+	if !output.Interactive() {
+		output.Warnf("Not running the hooks of template pack '%s' without confirmation, pass --pack-hooks to run them", pack.Name)
+		return false
+	}
+
+	fmt.Printf("🪝 Template pack '%s' runs these commands in the new project:\n", pack.Name)
+	for _, hook := range pack.Hooks {
+		fmt.Printf("   %s: %s\n", hook.Name, hook.Run)
+	}
+	fmt.Print("Run them? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 func validateProjectName(name string) error {
 	if len(name) == 0 {
 		return fmt.Errorf("project name cannot be empty")
@@ -78,6 +184,22 @@ func init() {
 	initCmd.Flags().BoolVar(&withAuth, "with-auth", false, "Include authentication scaffolding")
 	initCmd.Flags().BoolVar(&withDocker, "with-docker", true, "Include Docker configuration")
 	initCmd.Flags().BoolVar(&withTests, "with-tests", true, "Include testing infrastructure")
+	initCmd.Flags().BoolVar(&withRedis, "with-redis", false, "Add a Redis service to docker-compose.yml")
+	initCmd.Flags().BoolVar(&withRateLimit, "with-ratelimit", false, "Add token bucket rate limiting middleware")
+	initCmd.Flags().BoolVar(&withMetrics, "with-metrics", false, "Add Prometheus metrics and pprof on an admin port")
+	initCmd.Flags().BoolVar(&withTracing, "with-tracing", false, "Add OpenTelemetry tracing exported over OTLP")
+	initCmd.Flags().BoolVar(&withJobs, "with-jobs", false, "Add a database-backed background job queue and worker")
+	initCmd.Flags().StringVar(&ciProvider, "ci", "", "Generate a CI pipeline (github, gitlab)")
+	initCmd.Flags().StringVar(&templateSource, "template", "", "Template pack to use (git+<url>[@ref] or a local directory)")
+	initCmd.Flags().StringToStringVar(&templateOptions, "set", nil, "Template pack option values (key=value)")
+	initCmd.Flags().BoolVar(&refreshTemplate, "refresh-template", false, "Fetch the template pack again instead of using the cache")
+
+	initCmd.Flags().BoolVar(&hookOptions.Git, "git", false, "Initialize a git repository with an initial commit")
+	initCmd.Flags().BoolVar(&hookOptions.Tidy, "tidy", false, "Run 'go mod tidy' in the new project")
+	initCmd.Flags().BoolVar(&hookOptions.Vendor, "vendor", false, "Run 'go mod vendor' in the new project")
+	initCmd.Flags().BoolVar(&hookOptions.Offline, "offline", false, "Skip post-generation steps that need the network")
+	initCmd.Flags().BoolVar(&hookOptions.PackHooks, "pack-hooks", false, "Run the hooks declared by the template pack without asking")
+	initCmd.Flags().BoolVar(&noPackHooks, "no-hooks", false, "Don't run the hooks declared by the template pack")
 
 	rootCmd.AddCommand(initCmd)
 }
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/adipras/lupettogo/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var jobCmd = &cobra.Command{
+	Use:   "job [name]",
+	Short: "Generate a background job type",
+	Long: `Generate a background job in internal/jobs: a payload struct, a Handle
+method and a typed Enqueue helper on the queue, plus tests when the project
+has tests enabled. The project needs the job queue from 'init --with-jobs'.
+
+Examples:
+  lupettogo generate job send_email
+  lupettogo generate job rebuild_search_index`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generator.GenerateJob(args[0])
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(jobCmd)
+}
@@ -5,15 +5,45 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	moduleFields string
+	moduleCache  bool
+	moduleForce  bool
+)
+
 var moduleCmd = &cobra.Command{
-	Use:   "generate module [name]",
+	Use:   "module [name]",
 	Short: "Generate a new module (handler, service, model, repo)",
-	Args:  cobra.ExactArgs(1),
+	Long: `Generate a CRUD module: model, repository, service and handler, plus
+handler tests when the project has tests enabled. With --cache the repository
+also gets a cache-aside decorator, backed by Redis or an in-memory LRU as the
+cache config selects; the internal/cache package is generated the first time.
+Existing module files are left alone unless --force is given.
+
+Fields are given as name:type[:modifier...], separated by commas.
+Types: string, text, int, int64, uint, float, bool, time.
+Modifiers: required, unique, default=<value>.
+
+Examples:
+  lupettogo generate module product
+  lupettogo generate module product --fields "title:string:required,price:float,sku:string:unique"
+  lupettogo generate module order_item --fields "quantity:int:required,note:text"
+  lupettogo generate module product --cache
+  lupettogo generate module product --cache --force`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return generator.GenerateModule(args[0])
+		fields, err := generator.ParseFields(moduleFields)
+		if err != nil {
+			return err
+		}
+		return generator.GenerateModule(args[0], generator.ModuleOptions{Fields: fields, Cache: moduleCache, Force: moduleForce})
 	},
 }
 
 func init() {
-	rootCmd.AddCommand(moduleCmd)
+	moduleCmd.Flags().StringVar(&moduleFields, "fields", "", "Model fields as name:type[:modifier...], comma separated")
+	moduleCmd.Flags().BoolVar(&moduleCache, "cache", false, "Wrap the repository in a cache-aside decorator")
+	moduleCmd.Flags().BoolVar(&moduleForce, "force", false, "Overwrite existing module files")
+
+	generateCmd.AddCommand(moduleCmd)
 }
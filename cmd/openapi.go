@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/adipras/lupettogo/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	openapiOutput   string
+	openapiBasePath string
+	openapiVersion  string
+)
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Generate an OpenAPI 3 spec for the current project",
+	Long: `Generate an OpenAPI 3 spec from the swag-style annotations
+(@Summary, @Param, @Success, @Router, ...) on the project's handlers.
+
+Request and response schemas are derived from the structs in internal/models.
+The generated server serves the spec at /openapi.yaml and a Swagger UI at
+/swagger when running in debug mode.
+
+Examples:
+  lupettogo openapi
+  lupettogo openapi --out docs/openapi.yaml --base-path /api/v2`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generator.GenerateOpenAPI(generator.OpenAPIOptions{
+			Dir:      ".",
+			Output:   openapiOutput,
+			BasePath: openapiBasePath,
+			Version:  openapiVersion,
+		})
+	},
+}
+
+func init() {
+	openapiCmd.Flags().StringVar(&openapiOutput, "out", "openapi.yaml", "Path of the generated spec")
+	openapiCmd.Flags().StringVar(&openapiBasePath, "base-path", "/api/v1", "Base path the API routes are mounted on")
+	openapiCmd.Flags().StringVar(&openapiVersion, "api-version", "1.0.0", "Version reported in the spec info")
+
+	rootCmd.AddCommand(openapiCmd)
+}
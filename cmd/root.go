@@ -5,12 +5,19 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
 	"os"
+	"strings"
 
+	"github.com/adipras/lupettogo/internal/output"
 	"github.com/spf13/cobra"
 )
 
-
+var (
+	outputFormat   string
+	quiet          bool
+	nonInteractive bool
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -25,15 +32,48 @@ ready to extend with features like auth, RBAC, and multi-tenant support.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.Configure(outputFormat, quiet, nonInteractive); err != nil {
+			return err
+		}
+		if output.JSON() {
+			// Errors are reported in the JSON document, so cobra must not
+			// print them or the usage next to it
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+		}
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	cmd, err := rootCmd.ExecuteC()
+	command := strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+	if ferr := output.Finish(command, err); ferr != nil && err == nil {
+		err = ferr
+	}
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitError makes a command exit with a specific code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+func exitCode(err error) int {
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
 	}
+	return 1
 }
 
 func init() {
@@ -42,6 +82,9 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.lupettogo.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", output.FormatText, "Output format (text, json)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print warnings and errors")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt; use defaults and flag values")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/adipras/lupettogo/internal/generator"
+	"github.com/adipras/lupettogo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	templatesExportUser  bool
+	templatesExportForce bool
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Inspect and customise the templates used for generation",
+	Long: `Templates are looked up in four layers, first match wins:
+
+  1. project   .lupettogo/templates/ in the current project
+  2. pack      files/ of the template pack recorded in lupettogo.yaml, if any
+  3. user      ~/.config/lupettogo/templates/
+  4. embedded  the defaults compiled into LupettoGo
+
+Project files use their output path as template name (e.g. internal/server/server.go),
+module templates live under modules/ (e.g. modules/handler.go.tmpl).`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List templates and the layer each one resolves from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templates, err := generator.ListTemplates(".")
+		if err != nil {
+			return err
+		}
+
+		if output.JSON() {
+			output.Set("templates", templates)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TEMPLATE\tLAYER\tSOURCE")
+		for _, t := range templates {
+			source := t.Path
+			if source == "" {
+				source = "-"
+			} else if t.Overridden {
+				source += " (overrides default)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Layer, source)
+		}
+		return w.Flush()
+	},
+}
+
+var templatesExportCmd = &cobra.Command{
+	Use:   "export [dir]",
+	Short: "Export the default templates for editing",
+	Long: `Export the embedded default templates so they can be edited.
+
+Without a directory the templates are written to the project layer
+(.lupettogo/templates), or to the user layer with --user. Remove the files you
+don't change so they keep following LupettoGo updates.
+
+Examples:
+  lupettogo templates export
+  lupettogo templates export --user
+  lupettogo templates export ./my-templates`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := generator.ProjectTemplatesDir
+		if templatesExportUser {
+			userDir, err := generator.UserTemplatesDir()
+			if err != nil {
+				return fmt.Errorf("failed to locate user config directory: %w", err)
+			}
+			dir = userDir
+		}
+		if len(args) == 1 {
+			dir = args[0]
+		}
+
+		written, err := generator.ExportTemplates(dir, templatesExportForce)
+		if err != nil {
+			return err
+		}
+
+		output.Created(written...)
+		output.Printf("📦 Exported %d template(s) to %s\n", len(written), dir)
+		return nil
+	},
+}
+
+func init() {
+	templatesExportCmd.Flags().BoolVar(&templatesExportUser, "user", false, "Export to the user template directory")
+	templatesExportCmd.Flags().BoolVar(&templatesExportForce, "force", false, "Overwrite existing files")
+
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesExportCmd)
+	rootCmd.AddCommand(templatesCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/adipras/lupettogo/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var upgradeDryRun bool
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Bring the current project up to date with the latest templates",
+	Long: `Re-render the project and its modules with the templates of this LupettoGo
+release and merge the result into your files.
+
+The options recorded in lupettogo.yaml are used for rendering. Each file is
+three-way merged against the snapshot in .lupettogo/base written by the last
+generation: files you have not touched are updated, non-overlapping changes are
+merged, and overlapping changes are written with conflict markers.
+
+Examples:
+  lupettogo upgrade --dry-run
+  lupettogo upgrade`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := generator.UpgradeProject(generator.UpgradeOptions{
+			Dir:    ".",
+			DryRun: upgradeDryRun,
+		})
+		if err != nil {
+			return err
+		}
+
+		generator.PrintUpgradeReport(report, upgradeDryRun)
+		if len(report.Conflicts) > 0 {
+			return fmt.Errorf("upgrade finished with %d conflict(s)", len(report.Conflicts))
+		}
+		return nil
+	},
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "Show what would change without writing files")
+
+	rootCmd.AddCommand(upgradeCmd)
+}
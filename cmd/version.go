@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"fmt"
-
+	"github.com/adipras/lupettogo/internal/generator"
+	"github.com/adipras/lupettogo/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -12,10 +12,12 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of LupettoGo CLI",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("LupettoGo CLI %s 🐺\n", Version)
+		output.Printf("LupettoGo CLI %s 🐺\n", Version)
+		output.Set("version", Version)
 	},
 }
 
 func init() {
+	generator.Version = Version
 	rootCmd.AddCommand(versionCmd)
 }
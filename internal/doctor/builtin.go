@@ -0,0 +1,21 @@
+package doctor
+
+import "github.com/adipras/lupettogo/internal/generator"
+
+// The tools LupettoGo and generated projects use. The Go floor follows the
+// release generated projects target; the others are the oldest releases
+// that handle the generated Dockerfile, database setup and lint config.
+func init() {
+	Register(ToolCheck{Title: "Go", Bin: "go", Args: []string{"version"}, Version: ">= " + generator.GoVersion})
+	Register(ToolCheck{Title: "Git", Bin: "git", Args: []string{"--version"}})
+	Register(ToolCheck{Title: "PostgreSQL", Bin: "psql", Version: ">= 13", Optional: true})
+	Register(ToolCheck{Title: "MySQL", Bin: "mysql", Version: ">= 8.0", Optional: true})
+	Register(ToolCheck{Title: "Docker", Bin: "docker", Version: ">= 23.0", Optional: true})
+	Register(ToolCheck{
+		Title:    "golangci-lint",
+		Bin:      "golangci-lint",
+		Version:  "^2",
+		Optional: true,
+		Fix:      CommandFix(".", "go", "install", "github.com/golangci/golangci-lint/v2/cmd/golangci-lint@"+generator.LintVersion),
+	})
+}
@@ -0,0 +1,268 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/adipras/lupettogo/internal/generator"
+)
+
+// Severity says how a failing check is reported.
+type Severity string
+
+const (
+	// SeverityRequired checks fail doctor when they don't pass.
+	SeverityRequired Severity = "required"
+	// SeverityOptional checks only warn.
+	SeverityOptional Severity = "optional"
+)
+
+// failStatus is the status of a check of this severity that didn't pass.
+func (s Severity) failStatus() string {
+	if s == SeverityOptional {
+		return StatusWarning
+	}
+	return StatusError
+}
+
+// Check is something doctor verifies. Checks are run again after --fix
+// applies fixes, so Run must look at the current state every time.
+type Check interface {
+	Name() string
+	Severity() Severity
+	// Applies reports whether the check is relevant on this platform.
+	Applies() bool
+	Run() Result
+}
+
+// Func adapts fn into a Check that applies on every platform.
+func Func(name string, severity Severity, fn func() Result) Check {
+	return funcCheck{name: name, severity: severity, fn: fn}
+}
+
+type funcCheck struct {
+	name     string
+	severity Severity
+	fn       func() Result
+}
+
+func (c funcCheck) Name() string       { return c.name }
+func (c funcCheck) Severity() Severity { return c.severity }
+func (c funcCheck) Applies() bool      { return true }
+func (c funcCheck) Run() Result        { return c.fn() }
+
+// ToolCheck checks that a command is installed and, when Version is set,
+// that the version it reports satisfies that constraint.
+type ToolCheck struct {
+	Title string
+	Bin   string
+	// Args print the tool's version, --version unless set. A check without
+	// Args or Version only looks the tool up in PATH.
+	Args []string
+	// Version is a constraint such as ">= 1.24" or "^3.20".
+	Version  string
+	Optional bool
+	// Platforms limits the check to GOOS or GOOS/GOARCH values, such as
+	// "linux" or "darwin/arm64". Empty means every platform.
+	Platforms []string
+	Fix       *Fix
+}
+
+func (t ToolCheck) Name() string {
+	return t.Title
+}
+
+func (t ToolCheck) Severity() Severity {
+	if t.Optional {
+		return SeverityOptional
+	}
+	return SeverityRequired
+}
+
+func (t ToolCheck) Applies() bool {
+	return matchPlatform(t.Platforms, runtime.GOOS, runtime.GOARCH)
+}
+
+func (t ToolCheck) Run() Result {
+	constraint, err := ParseConstraint(t.Version)
+	if err != nil {
+		return NewResult(t.Title, StatusError, err.Error())
+	}
+
+	if t.Version == "" && len(t.Args) == 0 {
+		path, err := exec.LookPath(t.Bin)
+		if err != nil {
+			return t.missing()
+		}
+		return NewResult(t.Title, StatusOK, "found at "+path)
+	}
+
+	args := t.Args
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+	out, err := exec.Command(t.Bin, args...).CombinedOutput()
+	outStr := firstLine(string(out))
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return t.missing()
+		}
+		return t.failed(fmt.Sprintf("'%s %s' failed: %s", t.Bin, strings.Join(args, " "), outStr))
+	}
+
+	result := NewResult(t.Title, StatusOK, outStr)
+	result.Version = outStr
+	if t.Version == "" {
+		return result
+	}
+
+	v, ok := findVersion(outStr)
+	if !ok {
+		return t.failed(fmt.Sprintf("cannot tell the version from %q (required: %s)", outStr, constraint))
+	}
+	result.Version = v.String()
+	if !constraint.Check(v) {
+		failed := t.failed(fmt.Sprintf("version %s does not satisfy %s: %s", v, constraint, outStr))
+		failed.Version = result.Version
+		return failed
+	}
+	return result
+}
+
+func (t ToolCheck) missing() Result {
+	if t.Optional {
+		return t.failed("not found (optional)")
+	}
+	return t.failed("not found - please install " + t.Bin)
+}
+
+func (t ToolCheck) failed(message string) Result {
+	result := NewResult(t.Title, t.Severity().failStatus(), message)
+	result.Fix = t.Fix
+	return result
+}
+
+// matchPlatform reports whether goos/goarch is one of platforms, each a GOOS
+// or GOOS/GOARCH value. An empty list matches every platform.
+func matchPlatform(platforms []string, goos, goarch string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+	for _, platform := range platforms {
+		wantOS, wantArch, hasArch := strings.Cut(platform, "/")
+		if wantOS == goos && (!hasArch || wantArch == goarch) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
+
+// registry holds the checks run on every machine, in registration order.
+var registry []Check
+
+// Register adds a check doctor runs everywhere, not only in projects.
+func Register(check Check) {
+	registry = append(registry, check)
+}
+
+// Group is a set of checks run and reported together.
+type Group struct {
+	Title  string
+	Checks []Check
+}
+
+// Groups returns what doctor checks in dir: the registered environment
+// checks, and inside a generated project the checks declared by its template
+// pack and those of the project itself. Pack checks run commands the pack
+// chose, so the pack is only loaded when packChecks is set. Checks that
+// don't apply on this platform are left out.
+func Groups(dir string, packChecks bool) []Group {
+	groups := []Group{{Title: "environment", Checks: applicable(registry)}}
+	if !IsProject(dir) {
+		return groups
+	}
+
+	if pack := packGroup(dir, packChecks); pack != nil {
+		groups = append(groups, *pack)
+	}
+	return append(groups, Group{Title: "project", Checks: ProjectChecks(dir)})
+}
+
+// packGroup loads the checks of the template pack the project in dir was
+// generated from, or returns nil when it has none. Unless packChecks is set
+// the pack is not loaded and its checks are reported as skipped.
+func packGroup(dir string, packChecks bool) *Group {
+	manifest, err := generator.LoadManifest(dir)
+	if err != nil || manifest.Template == nil {
+		return nil
+	}
+
+	name := manifest.Template.Name
+	title := "template pack " + name
+	if !packChecks {
+		return &Group{Title: title, Checks: []Check{
+			Func(name, SeverityOptional, func() Result {
+				return NewResult(name, StatusSkipped, "not run, pass --pack-checks to run the commands the template pack declares")
+			}),
+		}}
+	}
+
+	pack, err := generator.LoadProjectPack(dir, manifest.Template)
+	if err != nil {
+		return &Group{Title: title, Checks: []Check{
+			Func(name, SeverityOptional, func() Result {
+				return NewResult(name, StatusWarning, "failed to load template pack: "+err.Error())
+			}),
+		}}
+	}
+	if len(pack.Checks) == 0 {
+		return nil
+	}
+
+	checks := make([]Check, 0, len(pack.Checks))
+	for _, check := range pack.Checks {
+		checks = append(checks, packCheck(dir, check))
+	}
+	return &Group{Title: title, Checks: applicable(checks)}
+}
+
+func packCheck(dir string, check generator.PackCheck) Check {
+	title := check.Name
+	if title == "" {
+		title = check.Command
+	}
+
+	var fix *Fix
+	if check.Install != "" {
+		fix = CommandFix(dir, generator.ShellCommand(check.Install)...)
+		fix.Description = "run '" + check.Install + "'"
+	}
+
+	return ToolCheck{
+		Title:     title,
+		Bin:       check.Command,
+		Args:      check.Args,
+		Version:   check.Version,
+		Optional:  check.Severity == string(SeverityOptional),
+		Platforms: check.Platforms,
+		Fix:       fix,
+	}
+}
+
+func applicable(checks []Check) []Check {
+	var kept []Check
+	for _, check := range checks {
+		if check.Applies() {
+			kept = append(kept, check)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,110 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adipras/lupettogo/internal/generator"
+)
+
+func TestMatchPlatform(t *testing.T) {
+	tests := []struct {
+		platforms []string
+		want      bool
+	}{
+		{nil, true},
+		{[]string{"linux"}, true},
+		{[]string{"darwin", "linux/amd64"}, true},
+		{[]string{"linux/arm64"}, false},
+		{[]string{"windows"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchPlatform(tt.platforms, "linux", "amd64"); got != tt.want {
+			t.Errorf("matchPlatform(%q, linux, amd64) = %v, want %v", tt.platforms, got, tt.want)
+		}
+	}
+}
+
+// packProject writes a project generated from a local template pack that
+// declares a check, and returns its directory.
+func packProject(t *testing.T, source string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"pack/" + generator.PackManifestName: `name: acme
+checks:
+  - name: acme tool
+    command: lupettogo-test-missing-tool
+    install: echo installing
+  - command: lupettogo-test-other-os
+    platforms: [plan9]
+`,
+		"pack/files/README.md": "acme\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifest := &generator.Manifest{
+		Module:   "example.com/acme/app",
+		Template: &generator.ManifestTemplate{Source: source, Name: "acme"},
+	}
+	if err := manifest.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func groupTitled(groups []Group, title string) *Group {
+	for i := range groups {
+		if groups[i].Title == title {
+			return &groups[i]
+		}
+	}
+	return nil
+}
+
+func TestPackChecksNeedOptIn(t *testing.T) {
+	// Loading this source would fail, so a skipped result proves it wasn't
+	dir := packProject(t, "git+file:///nonexistent/pack.git")
+
+	group := groupTitled(Groups(dir, false), "template pack acme")
+	if group == nil || len(group.Checks) != 1 {
+		t.Fatalf("pack group = %+v, want a single placeholder check", group)
+	}
+	result := group.Checks[0].Run()
+	if result.Status != StatusSkipped || result.Fix != nil {
+		t.Errorf("result = %+v, want skipped without a fix", result)
+	}
+	if groupTitled(Groups(dir, false), "project") == nil {
+		t.Error("project checks must still run")
+	}
+}
+
+func TestPackChecksWithOptIn(t *testing.T) {
+	dir := packProject(t, "pack")
+
+	group := groupTitled(Groups(dir, true), "template pack acme")
+	if group == nil {
+		t.Fatal("no template pack group")
+	}
+	if len(group.Checks) != 1 {
+		t.Fatalf("got %d checks, want only the one for this platform", len(group.Checks))
+	}
+
+	check := group.Checks[0]
+	if check.Name() != "acme tool" || check.Severity() != SeverityRequired {
+		t.Errorf("check = %s (%s)", check.Name(), check.Severity())
+	}
+	result := check.Run()
+	if result.Status != StatusError || result.Fix == nil || result.Fix.Description != "run 'echo installing'" {
+		t.Errorf("result = %+v, want a failure offering the install command", result)
+	}
+}
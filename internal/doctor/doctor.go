@@ -0,0 +1,66 @@
+// Package doctor holds the checks behind 'lupettogo doctor'.
+package doctor
+
+// Check statuses, from best to worst.
+const (
+	StatusOK      = "ok"
+	StatusSkipped = "skipped"
+	StatusWarning = "warning"
+	StatusError   = "error"
+)
+
+// statusCodes are the per-check codes reported in JSON output.
+var statusCodes = map[string]int{
+	StatusOK:      0,
+	StatusSkipped: 0,
+	StatusWarning: 1,
+	StatusError:   2,
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Code    int    `json:"code"`
+	Version string `json:"version,omitempty"`
+	Message string `json:"message,omitempty"`
+	Fix     *Fix   `json:"fix,omitempty"`
+}
+
+// Run runs every check in order.
+func Run(checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, check.Run())
+	}
+	return results
+}
+
+// NewResult builds a Result, deriving its code from status.
+func NewResult(name, status, message string) Result {
+	return Result{Name: name, Status: status, Code: statusCodes[status], Message: message}
+}
+
+// Exit codes of 'lupettogo doctor'.
+const (
+	ExitOK       = 0
+	ExitFailed   = 1
+	ExitWarnings = 2
+)
+
+// ExitCode summarises results: ExitFailed when any check failed, and
+// ExitWarnings when strict is set and any check warned.
+func ExitCode(results []Result, strict bool) int {
+	code := ExitOK
+	for _, result := range results {
+		switch result.Status {
+		case StatusError:
+			return ExitFailed
+		case StatusWarning:
+			if strict {
+				code = ExitWarnings
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,38 @@
+package doctor
+
+import "testing"
+
+func TestExitCode(t *testing.T) {
+	ok := NewResult("go", StatusOK, "")
+	skipped := NewResult("database", StatusSkipped, "")
+	warning := NewResult("docker", StatusWarning, "")
+	failed := NewResult("git", StatusError, "")
+
+	tests := []struct {
+		name    string
+		results []Result
+		strict  bool
+		want    int
+	}{
+		{"no checks", nil, false, ExitOK},
+		{"all passed", []Result{ok, skipped}, true, ExitOK},
+		{"warnings", []Result{ok, warning}, false, ExitOK},
+		{"warnings with strict", []Result{ok, warning}, true, ExitWarnings},
+		{"failure", []Result{ok, failed}, false, ExitFailed},
+		{"failure wins over warnings", []Result{warning, failed}, true, ExitFailed},
+		{"failure before warnings", []Result{failed, warning}, true, ExitFailed},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.results, tt.strict); got != tt.want {
+			t.Errorf("%s: ExitCode() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewResultCodes(t *testing.T) {
+	for status, want := range map[string]int{StatusOK: 0, StatusSkipped: 0, StatusWarning: 1, StatusError: 2} {
+		if got := NewResult("check", status, "").Code; got != want {
+			t.Errorf("code of %s = %d, want %d", status, got, want)
+		}
+	}
+}
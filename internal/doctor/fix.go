@@ -0,0 +1,160 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/adipras/lupettogo/internal/generator"
+)
+
+// Fix is a remediation a failing check offers to 'doctor --fix'.
+type Fix struct {
+	Description string       `json:"description"`
+	Apply       func() error `json:"-"`
+}
+
+// FixReport records what --fix did for one check.
+type FixReport struct {
+	Check   string `json:"check"`
+	Fix     string `json:"fix"`
+	Before  string `json:"before"`
+	After   string `json:"after,omitempty"`
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+// maxFixPasses bounds how often checks are re-run to pick up failures that
+// only show once an earlier fix is applied, such as a weak JWT_SECRET in a
+// freshly created .env.
+const maxFixPasses = 3
+
+// FixAll offers the fixes of failing checks to confirm, applies the accepted
+// ones and runs the checks again. It returns what was done for each check
+// with its status before and after, and the final results.
+func FixAll(checks []Check, results []Result, confirm func(Result) bool) ([]FixReport, []Result) {
+	var reports []FixReport
+	offered := map[string]bool{}
+
+	for pass := 0; pass < maxFixPasses; pass++ {
+		passReports := applyFixes(results, offered, confirm)
+		if len(passReports) == 0 {
+			break
+		}
+		reports = append(reports, passReports...)
+		results = Run(checks)
+	}
+
+	status := map[string]string{}
+	for _, result := range results {
+		status[result.Name] = result.Status
+	}
+	for i := range reports {
+		reports[i].After = status[reports[i].Check]
+	}
+	return reports, results
+}
+
+func applyFixes(results []Result, offered map[string]bool, confirm func(Result) bool) []FixReport {
+	var reports []FixReport
+	for _, result := range results {
+		if result.Fix == nil || result.Status == StatusOK || result.Status == StatusSkipped || offered[result.Name] {
+			continue
+		}
+		offered[result.Name] = true
+
+		report := FixReport{Check: result.Name, Fix: result.Fix.Description, Before: result.Status}
+		if confirm(result) {
+			if err := result.Fix.Apply(); err != nil {
+				report.Error = err.Error()
+			} else {
+				report.Applied = true
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// copyFileFix creates dst from src.
+func copyFileFix(src, dst string) *Fix {
+	return &Fix{
+		Description: fmt.Sprintf("create %s from %s", dst, src),
+		Apply: func() error {
+			content, err := os.ReadFile(src)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(dst, content, 0600)
+		},
+	}
+}
+
+// appendEnvFix adds the missing keys to an env file with their example
+// values.
+func appendEnvFix(path string, keys []string, example map[string]string) *Fix {
+	return &Fix{
+		Description: fmt.Sprintf("add %s to %s with the values from .env.example", strings.Join(keys, ", "), path),
+		Apply: func() error {
+			for _, key := range keys {
+				if err := setEnvValue(path, key, example[key]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// secretFix writes a random 256-bit hex secret to key.
+func secretFix(path, key string) *Fix {
+	return &Fix{
+		Description: fmt.Sprintf("generate a random %s in %s", key, path),
+		Apply: func() error {
+			secret, err := generator.RandomSecret()
+			if err != nil {
+				return err
+			}
+			return setEnvValue(path, key, secret)
+		},
+	}
+}
+
+// CommandFix runs a command in dir.
+func CommandFix(dir string, args ...string) *Fix {
+	return &Fix{
+		Description: "run '" + strings.Join(args, " ") + "'",
+		Apply: func() error {
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("%w: %s", err, lastLines(string(out), 5))
+			}
+			return nil
+		},
+	}
+}
+
+// setEnvValue replaces the KEY= line of an env file, or appends one.
+func setEnvValue(path, key, value string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	line := key + "=" + value
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	replaced := false
+	for i, existing := range lines {
+		name, _, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(existing), "export "), "=")
+		if ok && strings.TrimSpace(name) == key {
+			lines[i] = line
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
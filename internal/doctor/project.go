@@ -0,0 +1,280 @@
+package doctor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adipras/lupettogo/internal/generator"
+)
+
+// IsProject reports whether dir holds a generated project: one with a
+// manifest, or for projects older than the manifest, go.mod and .env.example.
+func IsProject(dir string) bool {
+	if exists(filepath.Join(dir, generator.ManifestName)) {
+		return true
+	}
+	return exists(filepath.Join(dir, "go.mod")) && exists(filepath.Join(dir, ".env.example"))
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ProjectChecks checks the generated project in dir: its go.mod against the
+// manifest, its .env against .env.example, the JWT secret, the database and
+// server ports, and that it builds.
+func ProjectChecks(dir string) []Check {
+	// Every check reads the files afresh so it sees what fixes changed
+	project := func(name string, severity Severity, check func(p *projectState) Result) Check {
+		return Func(name, severity, func() Result {
+			p, err := loadProjectState(dir)
+			if err != nil {
+				return NewResult(generator.ManifestName, StatusError, err.Error())
+			}
+			return check(p)
+		})
+	}
+
+	return []Check{
+		project("go.mod module", SeverityRequired, checkModule),
+		project(".env", SeverityRequired, checkEnv),
+		project("JWT secret", SeverityOptional, checkJWTSecret),
+		project("Database", SeverityRequired, checkDatabase),
+		project("Port", SeverityOptional, checkPort),
+		project("go build", SeverityRequired, checkBuild),
+	}
+}
+
+type projectState struct {
+	dir      string
+	manifest *generator.Manifest
+	env      map[string]string
+	envErr   error
+	example  map[string]string
+	// prefix starts the environment variables the project reads
+	prefix string
+}
+
+func loadProjectState(dir string) (*projectState, error) {
+	manifest, err := generator.LoadManifest(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	p := &projectState{dir: dir, manifest: manifest}
+	p.env, p.envErr = readEnvFile(p.envPath())
+	p.example, _ = readEnvFile(filepath.Join(dir, ".env.example"))
+
+	module := ""
+	if manifest != nil {
+		module = manifest.Module
+	} else {
+		module, _ = generator.ReadModulePath(dir)
+	}
+	if module != "" {
+		p.prefix = generator.EnvPrefix(module)
+	}
+	return p, nil
+}
+
+// envKey returns the variable the project reads a setting from: the
+// prefixed name, e.g. SHOP_DATABASE_HOST for DATABASE_HOST, or legacy for
+// projects generated before settings were prefixed.
+func (p *projectState) envKey(setting, legacy string) string {
+	if p.prefix != "" {
+		key := p.prefix + "_" + setting
+		if _, ok := p.example[key]; ok {
+			return key
+		}
+		if _, ok := p.env[key]; ok {
+			return key
+		}
+	}
+	return legacy
+}
+
+func (p *projectState) envPath() string {
+	return filepath.Join(p.dir, ".env")
+}
+
+func checkModule(p *projectState) Result {
+	const name = "go.mod module"
+
+	module, err := generator.ReadModulePath(p.dir)
+	if err != nil {
+		return NewResult(name, StatusError, err.Error())
+	}
+	manifest := p.manifest
+	if manifest == nil {
+		return NewResult(name, StatusSkipped, "no "+generator.ManifestName+" to compare with")
+	}
+	if module != manifest.Module {
+		return NewResult(name, StatusError, fmt.Sprintf("go.mod declares %q but %s records %q", module, generator.ManifestName, manifest.Module))
+	}
+	return NewResult(name, StatusOK, module)
+}
+
+func checkEnv(p *projectState) Result {
+	const name = ".env"
+
+	if errors.Is(p.envErr, os.ErrNotExist) {
+		if p.example == nil {
+			return NewResult(name, StatusSkipped, "no .env.example")
+		}
+		result := NewResult(name, StatusError, ".env is missing, copy it from .env.example")
+		result.Fix = copyFileFix(filepath.Join(p.dir, ".env.example"), p.envPath())
+		return result
+	}
+	if p.envErr != nil {
+		return NewResult(name, StatusError, p.envErr.Error())
+	}
+
+	var missing []string
+	for key := range p.example {
+		if _, ok := p.env[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		result := NewResult(name, StatusWarning, "missing keys from .env.example: "+strings.Join(missing, ", "))
+		result.Fix = appendEnvFix(p.envPath(), missing, p.example)
+		return result
+	}
+	return NewResult(name, StatusOK, fmt.Sprintf("%d keys", len(p.env)))
+}
+
+func checkJWTSecret(p *projectState) Result {
+	name := p.envKey("JWT_SECRET", "JWT_SECRET")
+
+	if _, ok := p.example[name]; !ok || p.env == nil {
+		return NewResult(name, StatusSkipped, "not used or no .env")
+	}
+
+	secret := p.env[name]
+	var problem string
+	switch {
+	case secret == "":
+		problem = "is empty"
+	case generator.IsPlaceholderSecret(secret):
+		problem = "is still the example value"
+	case len(secret) < 32:
+		problem = "is shorter than 32 characters"
+	}
+	if problem == "" {
+		return NewResult(name, StatusOK, "set")
+	}
+
+	result := NewResult(name, StatusWarning, name+" "+problem)
+	result.Fix = secretFix(p.envPath(), name)
+	return result
+}
+
+func checkDatabase(p *projectState) Result {
+	const name = "Database"
+	manifest, env, example := p.manifest, p.env, p.example
+
+	driver := envValue(p.envKey("DATABASE_DRIVER", "DB_DRIVER"), env, example, "")
+	if driver == "" && manifest != nil {
+		driver = manifest.DBDriver
+	}
+	defaultPort := "5432"
+	if driver == "mysql" {
+		defaultPort = "3306"
+	}
+
+	host := envValue(p.envKey("DATABASE_HOST", "DB_HOST"), env, example, "localhost")
+	port := envValue(p.envKey("DATABASE_PORT", "DB_PORT"), env, example, defaultPort)
+	address := net.JoinHostPort(host, port)
+	conn, err := net.DialTimeout("tcp", address, 2*time.Second)
+	if err != nil {
+		return NewResult(name, StatusError, fmt.Sprintf("%s not reachable at %s", driverName(driver), address))
+	}
+	conn.Close()
+	return NewResult(name, StatusOK, fmt.Sprintf("%s reachable at %s", driverName(driver), address))
+}
+
+func driverName(driver string) string {
+	if driver == "" {
+		return "database"
+	}
+	return driver
+}
+
+func checkPort(p *projectState) Result {
+	port := envValue(p.envKey("SERVER_PORT", "PORT"), p.env, p.example, "8080")
+	name := "Port " + port
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return NewResult(name, StatusWarning, "already in use, is the server already running?")
+	}
+	listener.Close()
+	return NewResult(name, StatusOK, "free")
+}
+
+func checkBuild(p *projectState) Result {
+	const name = "go build"
+
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = p.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		result := NewResult(name, StatusError, lastLines(string(out), 5))
+		result.Fix = CommandFix(p.dir, "go", "mod", "tidy")
+		return result
+	}
+	return NewResult(name, StatusOK, "builds")
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// envValue looks key up in .env, then .env.example, then falls back.
+func envValue(key string, env, example map[string]string, fallback string) string {
+	if value := env[key]; value != "" {
+		return value
+	}
+	if value := example[key]; value != "" {
+		return value
+	}
+	return fallback
+}
+
+// readEnvFile parses KEY=VALUE lines, ignoring blank lines and comments.
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return values, scanner.Err()
+}
@@ -0,0 +1,36 @@
+package doctor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckJWTSecret(t *testing.T) {
+	tests := []struct {
+		secret string
+		status string
+		want   string
+	}{
+		{secret: strings.Repeat("a1", 32), status: StatusOK},
+		{secret: "", status: StatusWarning, want: "is empty"},
+		{secret: "your-super-secret-jwt-key-here", status: StatusWarning, want: "example value"},
+		// Refused by the generated config in release mode as well
+		{secret: "password", status: StatusWarning, want: "example value"},
+		{secret: "Admin", status: StatusWarning, want: "example value"},
+		{secret: "short-but-random-3f9a", status: StatusWarning, want: "shorter than 32"},
+	}
+	for _, tt := range tests {
+		p := &projectState{
+			dir:     t.TempDir(),
+			example: map[string]string{"JWT_SECRET": ""},
+			env:     map[string]string{"JWT_SECRET": tt.secret},
+		}
+		result := checkJWTSecret(p)
+		if result.Status != tt.status || !strings.Contains(result.Message, tt.want) {
+			t.Errorf("checkJWTSecret(%q) = %s %q, want %s %q", tt.secret, result.Status, result.Message, tt.status, tt.want)
+		}
+		if tt.status != StatusOK && result.Fix == nil {
+			t.Errorf("checkJWTSecret(%q) offers no fix", tt.secret)
+		}
+	}
+}
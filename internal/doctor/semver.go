@@ -0,0 +1,135 @@
+package doctor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// version is a major.minor.patch version; missing parts are zero.
+type version [3]int
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// findVersion extracts the first version number from tool output such as
+// "go version go1.24.2 linux/amd64" or "Docker version 27.3.1, build ce12230".
+func findVersion(s string) (version, bool) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return version{}, false
+	}
+	var v version
+	for i, part := range match[1:] {
+		v[i], _ = strconv.Atoi(part)
+	}
+	return v, true
+}
+
+func parseVersion(s string) (version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || s == "" {
+		return version{}, fmt.Errorf("invalid version %q", s)
+	}
+	var v version
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return version{}, fmt.Errorf("invalid version %q", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+func (v version) compare(o version) int {
+	for i := range v {
+		if v[i] != o[i] {
+			if v[i] < o[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// Constraint is a set of version comparisons that must all hold, written
+// like ">= 1.24, < 2" or "^3.20". Supported operators are =, !=, >, >=, <,
+// <=, ~ (same minor) and ^ (same major).
+type Constraint struct {
+	raw   string
+	terms []constraintTerm
+}
+
+type constraintTerm struct {
+	op string
+	v  version
+}
+
+var constraintOps = []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"}
+
+// ParseConstraint parses a constraint; an empty string accepts any version.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" {
+		return c, nil
+	}
+
+	for _, part := range strings.Split(c.raw, ",") {
+		part = strings.TrimSpace(part)
+		op := "="
+		for _, candidate := range constraintOps {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+		v, err := parseVersion(part)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.terms = append(c.terms, constraintTerm{op: op, v: v})
+	}
+	return c, nil
+}
+
+// Check reports whether v satisfies every term of the constraint.
+func (c Constraint) Check(v version) bool {
+	for _, term := range c.terms {
+		cmp := v.compare(term.v)
+		ok := false
+		switch term.op {
+		case "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "~":
+			ok = cmp >= 0 && v[0] == term.v[0] && v[1] == term.v[1]
+		case "^":
+			ok = cmp >= 0 && v[0] == term.v[0]
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Constraint) String() string {
+	return c.raw
+}
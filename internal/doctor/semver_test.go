@@ -0,0 +1,98 @@
+package doctor
+
+import "testing"
+
+func TestFindVersion(t *testing.T) {
+	tests := []struct {
+		out  string
+		want version
+		ok   bool
+	}{
+		{"go version go1.24.2 linux/amd64", version{1, 24, 2}, true},
+		{"Docker version 27.3.1, build ce12230", version{27, 3, 1}, true},
+		{"libprotoc 3.21", version{3, 21, 0}, true},
+		{"psql (PostgreSQL) 16.4 (Homebrew)", version{16, 4, 0}, true},
+		{"GNU Make 4.3", version{4, 3, 0}, true},
+		{"no version here", version{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := findVersion(tt.out)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("findVersion(%q) = %v, %v; want %v, %v", tt.out, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want version
+		err  bool
+	}{
+		{"1", version{1, 0, 0}, false},
+		{"1.24", version{1, 24, 0}, false},
+		{"v3.20.1", version{3, 20, 1}, false},
+		{" 2.0 ", version{2, 0, 0}, false},
+		{"", version{}, true},
+		{"1.2.3.4", version{}, true},
+		{"1.x", version{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseVersion(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseVersion(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{">= one", "^", "1.2.3.4", ">= 1.2,", "=> 1.2"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) accepted an invalid constraint", s)
+		}
+	}
+}
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"", "0.0.1", true},
+		{"1.24", "1.24.0", true},
+		{"1.24", "1.24.1", false},
+		{"== 1.24.1", "1.24.1", true},
+		{"!= 1.24.1", "1.24.1", false},
+		{"!= 1.24.1", "1.24.2", true},
+		{">= 1.24", "1.24.0", true},
+		{">= 1.24", "1.23.9", false},
+		{">1.24", "1.24.0", false},
+		{">1.24", "1.24.1", true},
+		{"< 2", "1.99.99", true},
+		{"< 2", "2.0.0", false},
+		{"<= 2", "2.0.0", true},
+		{">= 1.24, < 2", "1.30.0", true},
+		{">= 1.24, < 2", "2.1.0", false},
+		{"~1.24", "1.24.9", true},
+		{"~1.24", "1.25.0", false},
+		{"~1.24.3", "1.24.2", false},
+		{"^3.20", "3.99.0", true},
+		{"^3.20", "3.19.0", false},
+		{"^3.20", "4.0.0", false},
+		{"^0.9", "0.10.0", true},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		v, err := parseVersion(tt.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Check(v); got != tt.want {
+			t.Errorf("%q.Check(%s) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/adipras/lupettogo/internal/output"
+)
+
+// cacheDir is the package 'generate module --cache' generates on first use.
+const cacheDir = "internal/cache"
+
+// generateCachePackage writes the cache package and records it in the
+// manifest as a project feature, so 'lupettogo upgrade' keeps it current.
+// It returns the modules the package needs that go.mod doesn't list yet.
+func generateCachePackage(fsys fs.FS, manifest *Manifest) ([]Dependency, error) {
+	before := manifest.projectData()
+	data := before
+	data.WithCache = true
+	data = data.withCatalogue()
+
+	files := map[string][]byte{}
+	if err := processTemplateFS(fsys, filepath.ToSlash(cacheDir), cacheDir, data, files); err != nil {
+		return nil, fmt.Errorf("failed to generate cache package: %w", err)
+	}
+
+	if err := writeFiles(".", files); err != nil {
+		return nil, fmt.Errorf("failed to generate cache package: %w", err)
+	}
+	for _, path := range sortedPaths(files) {
+		output.Printf("📄 Created %s\n", path)
+		output.Created(path)
+	}
+
+	if err := saveBaseSnapshot(".", files); err != nil {
+		return nil, err
+	}
+
+	manifest.Features.Cache = true
+	manifest.addFiles(files)
+
+	return newDependencies(before.Dependencies, data.Dependencies), nil
+}
+
+// newDependencies returns the modules in after that aren't in before.
+func newDependencies(before, after []Dependency) []Dependency {
+	known := map[string]bool{}
+	for _, dep := range before {
+		known[dep.Module] = true
+	}
+
+	var added []Dependency
+	for _, dep := range after {
+		if !known[dep.Module] {
+			added = append(added, dep)
+		}
+	}
+	return added
+}
+
+// dependencyArgs formats deps as 'go get' arguments.
+func dependencyArgs(deps []Dependency) []string {
+	args := make([]string, len(deps))
+	for i, dep := range deps {
+		args[i] = dep.Module + "@" + dep.Version
+	}
+	return args
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/adipras/lupettogo/internal/output"
+)
+
+// CIProviders lists the CI systems pipelines can be generated for.
+var CIProviders = []string{"github", "gitlab"}
+
+// ValidateCIProvider reports an error for providers without templates.
+func ValidateCIProvider(provider string) error {
+	for _, p := range CIProviders {
+		if provider == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported CI provider %q (supported: %s)", provider, strings.Join(CIProviders, ", "))
+}
+
+// renderCIFiles renders the shared CI templates and those of data.CI into
+// files, keyed by their path in the project.
+func renderCIFiles(fsys fs.FS, data ProjectData, files map[string][]byte) error {
+	if err := ValidateCIProvider(data.CI); err != nil {
+		return err
+	}
+	for _, dir := range []string{"common", data.CI} {
+		if err := processTemplateFS(fsys, path.Join("ci", dir), ".", data, files); err != nil {
+			return fmt.Errorf("failed to render CI templates: %w", err)
+		}
+	}
+	return nil
+}
+
+// GenerateCI adds a CI pipeline for provider to the project in the current
+// directory. Existing files are kept unless force is set.
+func GenerateCI(provider string, force bool) error {
+	if err := ValidateCIProvider(provider); err != nil {
+		return err
+	}
+
+	manifest, err := loadOrDetectManifest(".")
+	if err != nil {
+		return err
+	}
+	fsys, err := projectTemplateFS(".", manifest)
+	if err != nil {
+		return err
+	}
+
+	data := manifest.projectData()
+	data.CI = provider
+
+	files := map[string][]byte{}
+	if err := renderCIFiles(fsys, data, files); err != nil {
+		return err
+	}
+
+	if !force {
+		for _, path := range sortedPaths(files) {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+			}
+		}
+	}
+
+	if err := writeFiles(".", files); err != nil {
+		return fmt.Errorf("failed to write CI files: %w", err)
+	}
+	for _, path := range sortedPaths(files) {
+		output.Printf("📄 Created %s\n", path)
+		output.Created(path)
+	}
+
+	if err := saveBaseSnapshot(".", files); err != nil {
+		return err
+	}
+
+	manifest.Features.CI = provider
+	manifest.addFiles(files)
+	if err := manifest.Save("."); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ManifestName, err)
+	}
+
+	output.Printf("✅ %s CI pipeline created successfully!\n", provider)
+	if provider == "github" {
+		output.Printf("📝 Add a CODECOV_TOKEN repository secret to upload coverage\n")
+	}
+	return nil
+}
+
+// addFiles records files in the manifest, replacing existing entries for
+// the same paths.
+func (m *Manifest) addFiles(files map[string][]byte) {
+	added := manifestFiles(files)
+	replaced := map[string]bool{}
+	for _, file := range added {
+		replaced[file.Path] = true
+	}
+
+	kept := m.Files[:0]
+	for _, file := range m.Files {
+		if !replaced[file.Path] {
+			kept = append(kept, file)
+		}
+	}
+	m.Files = append(kept, added...)
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+}
@@ -0,0 +1,198 @@
+package generator
+
+// CI templates, one directory per provider plus files shared by all of them.
+// They live below ci/ in the template filesystem and are rendered into the
+// project root when a provider is selected.
+var ciTemplates = map[string]string{
+	"common/.golangci.yml": `# golangci-lint configuration, see https://golangci-lint.run/usage/configuration/
+version: "2"
+
+run:
+  timeout: 5m
+
+linters:
+  default: standard
+  enable:
+    - bodyclose
+    - gocritic
+    - misspell
+    - unconvert
+  exclusions:
+    presets:
+      - std-error-handling
+      - common-false-positives
+    paths:
+      - vendor
+
+formatters:
+  enable:
+    - gofmt
+    - goimports
+  settings:
+    goimports:
+      local-prefixes:
+        - {{.ProjectName}}
+`,
+
+	"github/.github/workflows/ci.yml": `name: CI
+
+on:
+  push:
+    branches: [main]
+  pull_request:
+
+permissions:
+  contents: read
+
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - name: Build
+        run: go build ./...
+      - name: Vet
+        run: go vet ./...
+      - name: golangci-lint
+        uses: golangci/golangci-lint-action@v8
+        with:
+          version: {{.LintVersion}}
+
+  test:
+    runs-on: ubuntu-latest
+    services:
+      db:
+        image: {{.DatabaseImage}}
+        env:
+{{- if eq .DBDriver "mysql"}}
+          MYSQL_ROOT_PASSWORD: password
+          MYSQL_DATABASE: {{.ProjectName}}_test
+        ports:
+          - 3306:3306
+        options: >-
+          --health-cmd "mysqladmin ping -h 127.0.0.1 -uroot -ppassword --silent"
+{{- else}}
+          POSTGRES_USER: postgres
+          POSTGRES_PASSWORD: password
+          POSTGRES_DB: {{.ProjectName}}_test
+        ports:
+          - 5432:5432
+        options: >-
+          --health-cmd "pg_isready -U postgres"
+{{- end}}
+          --health-interval 5s
+          --health-timeout 5s
+          --health-retries 10
+    env:
+      APP_ENV: test
+      {{envPrefix .ProjectName}}_DATABASE_HOST: 127.0.0.1
+      {{envPrefix .ProjectName}}_DATABASE_PORT: "{{if eq .DBDriver "mysql"}}3306{{else}}5432{{end}}"
+      {{envPrefix .ProjectName}}_DATABASE_USER: {{if eq .DBDriver "mysql"}}root{{else}}postgres{{end}}
+      {{envPrefix .ProjectName}}_DATABASE_PASSWORD: password
+      {{envPrefix .ProjectName}}_DATABASE_NAME: {{.ProjectName}}_test
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - name: Test
+        run: go test -race -coverprofile=coverage.out -covermode=atomic ./...
+      - name: Upload coverage
+        uses: codecov/codecov-action@v5
+        with:
+          files: coverage.out
+        env:
+          CODECOV_TOKEN: ${{"{{"}} secrets.CODECOV_TOKEN {{"}}"}}
+{{- if .WithDocker}}
+
+  docker:
+    runs-on: ubuntu-latest
+    needs: [lint, test]
+    steps:
+      - uses: actions/checkout@v4
+      - uses: docker/setup-buildx-action@v3
+      - name: Build image
+        uses: docker/build-push-action@v6
+        with:
+          context: .
+          push: false
+          tags: {{.ProjectName}}:${{"{{"}} github.sha {{"}}"}}
+          build-args: |
+            VERSION=${{"{{"}} github.ref_name {{"}}"}}
+            COMMIT=${{"{{"}} github.sha {{"}}"}}
+          cache-from: type=gha
+          cache-to: type=gha,mode=max
+{{- end}}
+`,
+
+	"gitlab/.gitlab-ci.yml": `stages:
+  - lint
+  - test
+{{- if .WithDocker}}
+  - build
+{{- end}}
+
+variables:
+  GOPATH: $CI_PROJECT_DIR/.go
+  GOFLAGS: -mod=mod
+
+cache:
+  key:
+    files:
+      - go.sum
+  paths:
+    - .go/pkg/mod/
+
+lint:
+  stage: lint
+  image: golangci/golangci-lint:{{.LintVersion}}-alpine
+  script:
+    - go build ./...
+    - go vet ./...
+    - golangci-lint run
+
+test:
+  stage: test
+  image: {{.BuilderImage}}
+  services:
+    - name: {{.DatabaseImage}}
+      alias: db
+  variables:
+{{- if eq .DBDriver "mysql"}}
+    MYSQL_ROOT_PASSWORD: password
+    MYSQL_DATABASE: {{.ProjectName}}_test
+{{- else}}
+    POSTGRES_USER: postgres
+    POSTGRES_PASSWORD: password
+    POSTGRES_DB: {{.ProjectName}}_test
+{{- end}}
+    APP_ENV: test
+    {{envPrefix .ProjectName}}_DATABASE_HOST: db
+    {{envPrefix .ProjectName}}_DATABASE_PORT: "{{if eq .DBDriver "mysql"}}3306{{else}}5432{{end}}"
+    {{envPrefix .ProjectName}}_DATABASE_USER: {{if eq .DBDriver "mysql"}}root{{else}}postgres{{end}}
+    {{envPrefix .ProjectName}}_DATABASE_PASSWORD: password
+    {{envPrefix .ProjectName}}_DATABASE_NAME: {{.ProjectName}}_test
+  script:
+    - go test -coverprofile=coverage.out -covermode=atomic ./...
+    - go tool cover -func=coverage.out | tail -1
+  coverage: '/total:\s+\(statements\)\s+(\d+\.\d+)%/'
+  artifacts:
+    paths:
+      - coverage.out
+{{- if .WithDocker}}
+
+docker:
+  stage: build
+  image: docker:27
+  services:
+    - docker:27-dind
+  variables:
+    DOCKER_BUILDKIT: "1"
+  script:
+    - docker build --build-arg VERSION=$CI_COMMIT_REF_NAME --build-arg COMMIT=$CI_COMMIT_SHORT_SHA -t {{.ProjectName}}:$CI_COMMIT_SHORT_SHA .
+{{- end}}
+`,
+}
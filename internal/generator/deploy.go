@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/adipras/lupettogo/internal/output"
+)
+
+// DeployTargets lists the platforms deployment manifests can be generated for.
+var DeployTargets = []string{"k8s"}
+
+// ValidateDeployTarget reports an error for targets without templates.
+func ValidateDeployTarget(target string) error {
+	for _, t := range DeployTargets {
+		if target == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported deploy target %q (supported: %s)", target, strings.Join(DeployTargets, ", "))
+}
+
+// renderDeployFiles renders the Kubernetes manifests and Helm chart the
+// project has enabled into files, keyed by their path in the project.
+func renderDeployFiles(fsys fs.FS, data ProjectData, files map[string][]byte) error {
+	if data.WithKubernetes {
+		if err := renderDeployDir(fsys, "deploy/k8s", data, files); err != nil {
+			return err
+		}
+	}
+	if data.WithHelm {
+		if err := renderDeployDir(fsys, "deploy/helm", data, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderDeployDir renders the templates below dir. The chart's own
+// templates are Helm's to render, so they are copied as they are.
+func renderDeployDir(fsys fs.FS, dir string, data ProjectData, files map[string][]byte) error {
+	err := fs.WalkDir(fsys, dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || shouldSkipFile(name, data) {
+			return err
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(name, "deploy/helm/templates/") {
+			files[name] = content
+			return nil
+		}
+
+		rendered, err := renderFile(name, name, content, data)
+		if err != nil {
+			return err
+		}
+		files[name] = rendered
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render deploy templates: %w", err)
+	}
+	return nil
+}
+
+// GenerateDeploy adds deployment manifests for target to the project in the
+// current directory: plain Kubernetes manifests, or a Helm chart when helm
+// is set. Existing files are kept unless force is set.
+func GenerateDeploy(target string, helm, force bool) error {
+	if err := ValidateDeployTarget(target); err != nil {
+		return err
+	}
+
+	manifest, err := loadOrDetectManifest(".")
+	if err != nil {
+		return err
+	}
+	fsys, err := projectTemplateFS(".", manifest)
+	if err != nil {
+		return err
+	}
+
+	before := manifest.projectData()
+	data := before
+	data.WithKubernetes = !helm
+	data.WithHelm = helm
+	data = data.withCatalogue()
+
+	files := map[string][]byte{}
+	if err := renderDeployFiles(fsys, data, files); err != nil {
+		return err
+	}
+
+	if !force {
+		for _, path := range sortedPaths(files) {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+			}
+		}
+	}
+
+	if err := writeFiles(".", files); err != nil {
+		return fmt.Errorf("failed to write deploy files: %w", err)
+	}
+	for _, path := range sortedPaths(files) {
+		output.Printf("📄 Created %s\n", path)
+		output.Created(path)
+	}
+
+	if err := saveBaseSnapshot(".", files); err != nil {
+		return err
+	}
+
+	if helm {
+		manifest.Features.Helm = true
+	} else {
+		manifest.Features.Kubernetes = true
+	}
+	manifest.addFiles(files)
+	if err := manifest.Save("."); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ManifestName, err)
+	}
+
+	deps := newDependencies(before.Dependencies, data.Dependencies)
+	downloaded, err := requireDependencies(".", deps)
+	if err != nil {
+		return err
+	}
+
+	name := resourceName(manifest.Module)
+	if helm {
+		output.Printf("✅ Helm chart created successfully!\n")
+		output.Printf("📝 Don't forget to:\n")
+		output.Printf("   - Set image.repository and the database settings in deploy/helm/values.yaml\n")
+		output.Printf("   - Pass the secrets at install time, e.g. helm install %s deploy/helm --set secrets.%s=...\n", name, EnvPrefix(manifest.Module)+"_JWT_SECRET")
+	} else {
+		output.Printf("✅ Kubernetes manifests created successfully!\n")
+		output.Printf("📝 Don't forget to:\n")
+		output.Printf("   - Set the image and the database settings in deploy/k8s\n")
+		output.Printf("   - Fill in deploy/k8s/secret.yaml from your secret store, or create the %s-secrets Secret yourself\n", name)
+		output.Printf("   - Apply them with 'kubectl apply -k deploy/k8s'\n")
+	}
+	if !downloaded {
+		output.Printf("   - Run 'go mod tidy' to download the manifest tests' dependencies\n")
+	}
+	return nil
+}
+
+// resourceName turns a module path into a Kubernetes resource name: lower
+// case letters, digits and dashes, at most 63 characters.
+func resourceName(module string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, kebabCase(path.Base(module)))
+
+	switch {
+	case name == "":
+		name = "app"
+	case name[0] >= '0' && name[0] <= '9':
+		// Service names must start with a letter
+		name = "app-" + name
+	}
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
@@ -0,0 +1,1087 @@
+package generator
+
+// Deployment templates, rendered by 'lupettogo generate deploy' into deploy/.
+// The files in helm/templates/ are Helm templates and are copied unrendered.
+
+var deployTemplates = map[string]string{
+	"k8s/kustomization.yaml": `{{- $name := resourceName .ProjectName -}}
+# Deploys {{$name}} with: kubectl apply -k deploy/k8s
+# Point the image at your registry and release with e.g.
+#   kustomize edit set image {{$name}}=registry.example.com/{{$name}}:v1.0.0
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+
+resources:
+  - configmap.yaml
+  - secret.yaml
+  - deployment.yaml
+{{- if .WithJobs}}
+  - worker.yaml
+{{- end}}
+  - service.yaml
+  - hpa.yaml
+
+images:
+  - name: {{$name}}
+    newTag: latest`,
+
+	"k8s/configmap.yaml": `{{- $env := envPrefix .ProjectName}}{{$name := resourceName .ProjectName -}}
+# Settings for the production profile as environment variables: every config
+# key maps to {{$env}}_<KEY>, see config/config.yaml. Secrets go in secret.yaml.
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{$name}}-config
+  labels:
+    app.kubernetes.io/name: {{$name}}
+data:
+  APP_ENV: production
+  # deployment.yaml expects the server on 8080
+  {{$env}}_SERVER_PORT: "8080"
+  # The database Service or managed instance to connect to
+  {{$env}}_DATABASE_HOST: {{.DBDriver}}
+  {{$env}}_DATABASE_PORT: "{{if eq .DBDriver "mysql"}}3306{{else}}5432{{end}}"
+  {{$env}}_DATABASE_USER: {{if eq .DBDriver "mysql"}}root{{else}}postgres{{end}}
+  {{$env}}_DATABASE_NAME: {{.ProjectName}}_db
+{{- if .WithRateLimit}}
+  # Replicas share rate limits through Redis
+  {{$env}}_RATELIMIT_STORE: redis
+  {{$env}}_REDIS_ADDR: redis:6379
+{{- end}}
+{{- if .WithMetrics}}
+  # deployment.yaml expects /metrics on 9090
+  {{$env}}_METRICS_ADMIN_PORT: "9090"
+{{- end}}
+{{- if .WithTracing}}
+  # An OpenTelemetry Collector reachable from the pods
+  {{$env}}_TRACING_ENABLED: "false"
+  {{$env}}_TRACING_ENDPOINT: otel-collector:4318
+{{- end}}`,
+
+	"k8s/secret.yaml": `{{- $env := envPrefix .ProjectName}}{{$name := resourceName .ProjectName -}}
+# Fill these in from your secret store before applying and don't commit the
+# real values; the server refuses to start in production while they are
+# empty. Or create the Secret yourself and drop this file from
+# kustomization.yaml:
+#   kubectl create secret generic {{$name}}-secrets \
+#     --from-literal={{$env}}_DATABASE_PASSWORD=... --from-literal={{$env}}_JWT_SECRET=...
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{$name}}-secrets
+  labels:
+    app.kubernetes.io/name: {{$name}}
+type: Opaque
+stringData:
+  {{$env}}_DATABASE_PASSWORD: ""
+  {{$env}}_JWT_SECRET: ""
+{{- if .WithRateLimit}}
+  {{$env}}_REDIS_PASSWORD: ""
+{{- end}}`,
+
+	"k8s/deployment.yaml": `{{- $env := envPrefix .ProjectName}}{{$name := resourceName .ProjectName -}}
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{$name}}
+  labels:
+    app.kubernetes.io/name: {{$name}}
+    app.kubernetes.io/component: api
+spec:
+  # hpa.yaml scales between 2 and 10 replicas
+  replicas: 2
+  revisionHistoryLimit: 5
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{$name}}
+      app.kubernetes.io/component: api
+  strategy:
+    type: RollingUpdate
+    rollingUpdate:
+      maxSurge: 1
+      maxUnavailable: 0
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{$name}}
+        app.kubernetes.io/component: api
+{{- if .WithMetrics}}
+      annotations:
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "9090"
+        prometheus.io/path: /metrics
+{{- end}}
+    spec:
+      # Service links would add variables such as {{$env}}_DATABASE_PORT for a
+      # Service named {{$name}}-database, which the config would read
+      enableServiceLinks: false
+      # Longer than the server's 10 second shutdown timeout
+      terminationGracePeriodSeconds: 30
+      securityContext:
+        # The nonroot user of the distroless image
+        runAsNonRoot: true
+        runAsUser: 65532
+        runAsGroup: 65532
+        seccompProfile:
+          type: RuntimeDefault
+      containers:
+        - name: app
+          image: {{$name}}:latest
+          imagePullPolicy: IfNotPresent
+          ports:
+            - name: http
+              containerPort: 8080
+{{- if .WithMetrics}}
+            - name: admin
+              containerPort: 9090
+{{- end}}
+          envFrom:
+            - configMapRef:
+                name: {{$name}}-config
+            - secretRef:
+                name: {{$name}}-secrets
+          # The startup probe gives the server a minute to connect to the
+          # database before the liveness probe takes over
+          startupProbe:
+            httpGet:
+              path: /health
+              port: http
+            periodSeconds: 5
+            failureThreshold: 12
+          livenessProbe:
+            httpGet:
+              path: /health
+              port: http
+            periodSeconds: 10
+            timeoutSeconds: 5
+            failureThreshold: 3
+          readinessProbe:
+            httpGet:
+              path: /health
+              port: http
+            periodSeconds: 5
+            timeoutSeconds: 3
+            failureThreshold: 2
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              memory: 256Mi
+          securityContext:
+            allowPrivilegeEscalation: false
+            readOnlyRootFilesystem: true
+            capabilities:
+              drop: ["ALL"]`,
+
+	"k8s/worker.yaml": `{{- $name := resourceName .ProjectName -}}
+# The background job worker: the same image and settings as the API, started
+# with /app/worker. Scale the replicas with the queue.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{$name}}-worker
+  labels:
+    app.kubernetes.io/name: {{$name}}
+    app.kubernetes.io/component: worker
+spec:
+  replicas: 1
+  revisionHistoryLimit: 5
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{$name}}
+      app.kubernetes.io/component: worker
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{$name}}
+        app.kubernetes.io/component: worker
+    spec:
+      enableServiceLinks: false
+      # Lets a job in progress finish within jobs.timeout, 5 minutes by default
+      terminationGracePeriodSeconds: 330
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 65532
+        runAsGroup: 65532
+        seccompProfile:
+          type: RuntimeDefault
+      containers:
+        - name: worker
+          image: {{$name}}:latest
+          imagePullPolicy: IfNotPresent
+          command: ["/app/worker"]
+          envFrom:
+            - configMapRef:
+                name: {{$name}}-config
+            - secretRef:
+                name: {{$name}}-secrets
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              memory: 256Mi
+          securityContext:
+            allowPrivilegeEscalation: false
+            readOnlyRootFilesystem: true
+            capabilities:
+              drop: ["ALL"]`,
+
+	"k8s/service.yaml": `{{- $name := resourceName .ProjectName -}}
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{$name}}
+  labels:
+    app.kubernetes.io/name: {{$name}}
+    app.kubernetes.io/component: api
+spec:
+  type: ClusterIP
+  selector:
+    app.kubernetes.io/name: {{$name}}
+    app.kubernetes.io/component: api
+  ports:
+    - name: http
+      port: 80
+      targetPort: http`,
+
+	"k8s/hpa.yaml": `{{- $name := resourceName .ProjectName -}}
+# Adds replicas when the API's pods average over 70% of their requested CPU
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{$name}}
+  labels:
+    app.kubernetes.io/name: {{$name}}
+    app.kubernetes.io/component: api
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{$name}}
+  minReplicas: 2
+  maxReplicas: 10
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 70`,
+
+	"k8s/manifests_test.go": `{{- $env := envPrefix .ProjectName}}{{$name := resourceName .ProjectName -}}
+package k8s_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/applyconfigurations"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// kustomizationResources returns the files kustomization.yaml lists.
+func kustomizationResources(t *testing.T) []string {
+	content, err := os.ReadFile("kustomization.yaml")
+	require.NoError(t, err)
+	var kustomization struct {
+		Resources []string ` + "`" + `json:"resources"` + "`" + `
+	}
+	require.NoError(t, yaml.Unmarshal(content, &kustomization))
+	return kustomization.Resources
+}
+
+// loadManifests reads the objects in the files kustomization.yaml lists.
+func loadManifests(t *testing.T) []*unstructured.Unstructured {
+	var objects []*unstructured.Unstructured
+	for _, file := range kustomizationResources(t) {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+		for {
+			var object map[string]any
+			err := decoder.Decode(&object)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err, file)
+			if object != nil {
+				objects = append(objects, &unstructured.Unstructured{Object: object})
+			}
+		}
+	}
+	return objects
+}
+
+// find returns the object of kind named name, decoded into into.
+func find(t *testing.T, objects []*unstructured.Unstructured, kind, name string, into any) {
+	for _, object := range objects {
+		if object.GetKind() == kind && object.GetName() == name {
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, into))
+			return
+		}
+	}
+	t.Fatalf("no %s named %s", kind, name)
+}
+
+// TestManifests_MatchSchemas checks every object against the OpenAPI schemas
+// of the Kubernetes API, which client-go embeds, so no cluster or network is
+// needed. Unknown fields and values of the wrong type fail the test.
+func TestManifests_MatchSchemas(t *testing.T) {
+	converter := applyconfigurations.NewTypeConverter(scheme.Scheme)
+
+	objects := loadManifests(t)
+	require.NotEmpty(t, objects)
+	for _, object := range objects {
+		_, err := converter.ObjectToTyped(object)
+		assert.NoError(t, err, "%s %s", object.GetKind(), object.GetName())
+	}
+}
+
+func TestKustomization_ListsEveryManifest(t *testing.T) {
+	files, err := filepath.Glob("*.yaml")
+	require.NoError(t, err)
+	var manifests []string
+	for _, file := range files {
+		if file != "kustomization.yaml" {
+			manifests = append(manifests, file)
+		}
+	}
+
+	assert.ElementsMatch(t, manifests, kustomizationResources(t))
+}
+
+func TestDeployment_ProbesHealthEndpoint(t *testing.T) {
+	var deployment appsv1.Deployment
+	find(t, loadManifests(t), "Deployment", "{{$name}}", &deployment)
+	require.Len(t, deployment.Spec.Template.Spec.Containers, 1)
+	container := deployment.Spec.Template.Spec.Containers[0]
+
+	for name, probe := range map[string]*corev1.Probe{
+		"startup":   container.StartupProbe,
+		"liveness":  container.LivenessProbe,
+		"readiness": container.ReadinessProbe,
+	} {
+		require.NotNil(t, probe, name)
+		require.NotNil(t, probe.HTTPGet, name)
+		assert.Equal(t, "/health", probe.HTTPGet.Path, name)
+		assert.Equal(t, "http", probe.HTTPGet.Port.String(), name)
+	}
+	require.NotEmpty(t, container.Ports)
+	assert.Equal(t, "http", container.Ports[0].Name)
+	assert.Equal(t, int32(8080), container.Ports[0].ContainerPort)
+}
+
+func TestManifests_AreWiredTogether(t *testing.T) {
+	objects := loadManifests(t)
+	var deployment appsv1.Deployment
+	find(t, objects, "Deployment", "{{$name}}", &deployment)
+	podLabels := deployment.Spec.Template.Labels
+
+	// The Deployment and the Service select the API's pods
+	for key, value := range deployment.Spec.Selector.MatchLabels {
+		assert.Equal(t, value, podLabels[key], "selector label %s", key)
+	}
+	var service corev1.Service
+	find(t, objects, "Service", "{{$name}}", &service)
+	for key, value := range service.Spec.Selector {
+		assert.Equal(t, value, podLabels[key], "service selector label %s", key)
+	}
+	// The Service sends traffic to a port the container declares
+	var portNames []string
+	for _, port := range deployment.Spec.Template.Spec.Containers[0].Ports {
+		portNames = append(portNames, port.Name)
+	}
+	for _, port := range service.Spec.Ports {
+		assert.Contains(t, portNames, port.TargetPort.String())
+	}
+
+	// The HPA scales the API
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	find(t, objects, "HorizontalPodAutoscaler", "{{$name}}", &hpa)
+	assert.Equal(t, "Deployment", hpa.Spec.ScaleTargetRef.Kind)
+	assert.Equal(t, deployment.Name, hpa.Spec.ScaleTargetRef.Name)
+
+	// The settings come from the ConfigMap and Secret in the manifests
+	var configMap corev1.ConfigMap
+	find(t, objects, "ConfigMap", "{{$name}}-config", &configMap)
+	var secret corev1.Secret
+	find(t, objects, "Secret", "{{$name}}-secrets", &secret)
+	for _, source := range deployment.Spec.Template.Spec.Containers[0].EnvFrom {
+		switch {
+		case source.ConfigMapRef != nil:
+			assert.Equal(t, configMap.Name, source.ConfigMapRef.Name)
+		case source.SecretRef != nil:
+			assert.Equal(t, secret.Name, source.SecretRef.Name)
+		}
+	}
+	assert.Equal(t, "8080", configMap.Data["{{$env}}_SERVER_PORT"])
+	assert.Equal(t, "production", configMap.Data["APP_ENV"])
+
+	// Only the application's own variables, so none is mistyped
+	for key := range configMap.Data {
+		assert.True(t, key == "APP_ENV" || strings.HasPrefix(key, "{{$env}}_"), "unexpected variable %s", key)
+	}
+	for key := range secret.StringData {
+		assert.True(t, strings.HasPrefix(key, "{{$env}}_"), "unexpected variable %s", key)
+	}
+}`,
+
+	"helm/Chart.yaml": `{{- $name := resourceName .ProjectName -}}
+apiVersion: v2
+name: {{$name}}
+description: Deploys the {{.ProjectName}} API{{if .WithJobs}} and its job worker{{end}}
+type: application
+# The chart's own version; bump it when the chart changes
+version: 0.1.0
+# The image tag deployed unless image.tag is set
+appVersion: "latest"`,
+
+	"helm/.helmignore": `# Patterns ignored when packaging the chart
+.DS_Store
+*.swp
+*.bak
+*.tmp
+*.orig
+*~
+# The chart's Go tests
+*.go`,
+
+	"helm/values.yaml": `{{- $env := envPrefix .ProjectName}}{{$name := resourceName .ProjectName -}}
+# Default values for the {{$name}} chart; override them with --set or -f.
+
+replicaCount: 2
+
+image:
+  repository: {{$name}}
+  # Defaults to the chart's appVersion
+  tag: ""
+  pullPolicy: IfNotPresent
+
+imagePullSecrets: []
+nameOverride: ""
+fullnameOverride: ""
+
+service:
+  type: ClusterIP
+  port: 80
+
+# Settings for the production profile, as environment variables in a
+# ConfigMap: every config key maps to {{$env}}_<KEY>, see config/config.yaml.
+# The Deployment expects the server on 8080{{if .WithMetrics}} and /metrics on 9090{{end}}.
+config:
+  APP_ENV: production
+  {{$env}}_SERVER_PORT: "8080"
+  # The database Service or managed instance to connect to
+  {{$env}}_DATABASE_HOST: {{.DBDriver}}
+  {{$env}}_DATABASE_PORT: "{{if eq .DBDriver "mysql"}}3306{{else}}5432{{end}}"
+  {{$env}}_DATABASE_USER: {{if eq .DBDriver "mysql"}}root{{else}}postgres{{end}}
+  {{$env}}_DATABASE_NAME: {{.ProjectName}}_db
+{{- if .WithRateLimit}}
+  # Replicas share rate limits through Redis
+  {{$env}}_RATELIMIT_STORE: redis
+  {{$env}}_REDIS_ADDR: redis:6379
+{{- end}}
+{{- if .WithMetrics}}
+  {{$env}}_METRICS_ADMIN_PORT: "9090"
+{{- end}}
+{{- if .WithTracing}}
+  # An OpenTelemetry Collector reachable from the pods
+  {{$env}}_TRACING_ENABLED: "false"
+  {{$env}}_TRACING_ENDPOINT: otel-collector:4318
+{{- end}}
+
+# Secret settings, stored in a Secret. Pass them at install time rather than
+# committing them, e.g. --set secrets.{{$env}}_JWT_SECRET=..., or name a
+# Secret you manage yourself in existingSecret. The server refuses to start
+# in production while they are empty.
+secrets:
+  {{$env}}_DATABASE_PASSWORD: ""
+  {{$env}}_JWT_SECRET: ""
+{{- if .WithRateLimit}}
+  {{$env}}_REDIS_PASSWORD: ""
+{{- end}}
+existingSecret: ""
+
+resources:
+  requests:
+    cpu: 100m
+    memory: 128Mi
+  limits:
+    memory: 256Mi
+
+# Adds replicas when the API's pods average over the target share of their
+# requested CPU; replicaCount is ignored while enabled
+autoscaling:
+  enabled: true
+  minReplicas: 2
+  maxReplicas: 10
+  targetCPUUtilizationPercentage: 70
+
+# Prometheus scrape annotations for the admin port
+metrics:
+  enabled: {{.WithMetrics}}
+{{- if .WithJobs}}
+
+# The background job worker, started from the same image with /app/worker
+worker:
+  enabled: true
+  replicaCount: 1
+  # Lets a job in progress finish within jobs.timeout, 5 minutes by default
+  terminationGracePeriodSeconds: 330
+  resources:
+    requests:
+      cpu: 100m
+      memory: 128Mi
+    limits:
+      memory: 256Mi
+{{- end}}
+
+podAnnotations: {}
+
+# The nonroot user of the distroless image
+podSecurityContext:
+  runAsNonRoot: true
+  runAsUser: 65532
+  runAsGroup: 65532
+  seccompProfile:
+    type: RuntimeDefault
+
+securityContext:
+  allowPrivilegeEscalation: false
+  readOnlyRootFilesystem: true
+  capabilities:
+    drop: ["ALL"]
+
+nodeSelector: {}
+tolerations: []
+affinity: {}`,
+
+	"helm/templates/_helpers.tpl": `{{/* The chart name, or nameOverride */}}
+{{- define "app.name" -}}
+{{- default .Chart.Name .Values.nameOverride | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/* The name of every object, qualified by the release unless it already contains the chart name */}}
+{{- define "app.fullname" -}}
+{{- if .Values.fullnameOverride }}
+{{- .Values.fullnameOverride | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- $name := default .Chart.Name .Values.nameOverride }}
+{{- if contains $name .Release.Name }}
+{{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- printf "%s-%s" .Release.Name $name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{- define "app.labels" -}}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" }}
+{{ include "app.selectorLabels" . }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+
+{{- define "app.selectorLabels" -}}
+app.kubernetes.io/name: {{ include "app.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+
+{{/* The Secret the settings in .Values.secrets are read from */}}
+{{- define "app.secretName" -}}
+{{- default (printf "%s-secrets" (include "app.fullname" .)) .Values.existingSecret }}
+{{- end }}
+
+{{- define "app.image" -}}
+{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}
+{{- end }}`,
+
+	"helm/templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "app.fullname" . }}-config
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+data:
+  {{- range $key, $value := .Values.config }}
+  {{ $key }}: {{ $value | toString | quote }}
+  {{- end }}`,
+
+	"helm/templates/secret.yaml": `{{- if not .Values.existingSecret }}
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ include "app.secretName" . }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+type: Opaque
+stringData:
+  {{- range $key, $value := .Values.secrets }}
+  {{ $key }}: {{ $value | toString | quote }}
+  {{- end }}
+{{- end }}`,
+
+	"helm/templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "app.fullname" . }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+    app.kubernetes.io/component: api
+spec:
+  {{- if not .Values.autoscaling.enabled }}
+  replicas: {{ .Values.replicaCount }}
+  {{- end }}
+  revisionHistoryLimit: 5
+  selector:
+    matchLabels:
+      {{- include "app.selectorLabels" . | nindent 6 }}
+      app.kubernetes.io/component: api
+  strategy:
+    type: RollingUpdate
+    rollingUpdate:
+      maxSurge: 1
+      maxUnavailable: 0
+  template:
+    metadata:
+      annotations:
+        # Restarts the pods when the settings change
+        checksum/config: {{ include (print $.Template.BasePath "/configmap.yaml") . | sha256sum }}
+        checksum/secrets: {{ include (print $.Template.BasePath "/secret.yaml") . | sha256sum }}
+        {{- if .Values.metrics.enabled }}
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "9090"
+        prometheus.io/path: /metrics
+        {{- end }}
+        {{- with .Values.podAnnotations }}
+        {{- toYaml . | nindent 8 }}
+        {{- end }}
+      labels:
+        {{- include "app.selectorLabels" . | nindent 8 }}
+        app.kubernetes.io/component: api
+    spec:
+      {{- with .Values.imagePullSecrets }}
+      imagePullSecrets:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      # Service links would add variables that the config could read as
+      # settings, e.g. for a Service named like the project
+      enableServiceLinks: false
+      # Longer than the server's 10 second shutdown timeout
+      terminationGracePeriodSeconds: 30
+      securityContext:
+        {{- toYaml .Values.podSecurityContext | nindent 8 }}
+      containers:
+        - name: app
+          image: {{ include "app.image" . | quote }}
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: 8080
+            {{- if .Values.metrics.enabled }}
+            - name: admin
+              containerPort: 9090
+            {{- end }}
+          envFrom:
+            - configMapRef:
+                name: {{ include "app.fullname" . }}-config
+            - secretRef:
+                name: {{ include "app.secretName" . }}
+          # The startup probe gives the server a minute to connect to the
+          # database before the liveness probe takes over
+          startupProbe:
+            httpGet:
+              path: /health
+              port: http
+            periodSeconds: 5
+            failureThreshold: 12
+          livenessProbe:
+            httpGet:
+              path: /health
+              port: http
+            periodSeconds: 10
+            timeoutSeconds: 5
+            failureThreshold: 3
+          readinessProbe:
+            httpGet:
+              path: /health
+              port: http
+            periodSeconds: 5
+            timeoutSeconds: 3
+            failureThreshold: 2
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+          securityContext:
+            {{- toYaml .Values.securityContext | nindent 12 }}
+      {{- with .Values.nodeSelector }}
+      nodeSelector:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.affinity }}
+      affinity:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.tolerations }}
+      tolerations:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}`,
+
+	"helm/templates/worker.yaml": `{{- if .Values.worker.enabled }}
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "app.fullname" . }}-worker
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+    app.kubernetes.io/component: worker
+spec:
+  replicas: {{ .Values.worker.replicaCount }}
+  revisionHistoryLimit: 5
+  selector:
+    matchLabels:
+      {{- include "app.selectorLabels" . | nindent 6 }}
+      app.kubernetes.io/component: worker
+  template:
+    metadata:
+      annotations:
+        checksum/config: {{ include (print $.Template.BasePath "/configmap.yaml") . | sha256sum }}
+        checksum/secrets: {{ include (print $.Template.BasePath "/secret.yaml") . | sha256sum }}
+        {{- with .Values.podAnnotations }}
+        {{- toYaml . | nindent 8 }}
+        {{- end }}
+      labels:
+        {{- include "app.selectorLabels" . | nindent 8 }}
+        app.kubernetes.io/component: worker
+    spec:
+      {{- with .Values.imagePullSecrets }}
+      imagePullSecrets:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      enableServiceLinks: false
+      terminationGracePeriodSeconds: {{ .Values.worker.terminationGracePeriodSeconds }}
+      securityContext:
+        {{- toYaml .Values.podSecurityContext | nindent 8 }}
+      containers:
+        - name: worker
+          image: {{ include "app.image" . | quote }}
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          command: ["/app/worker"]
+          envFrom:
+            - configMapRef:
+                name: {{ include "app.fullname" . }}-config
+            - secretRef:
+                name: {{ include "app.secretName" . }}
+          resources:
+            {{- toYaml .Values.worker.resources | nindent 12 }}
+          securityContext:
+            {{- toYaml .Values.securityContext | nindent 12 }}
+      {{- with .Values.nodeSelector }}
+      nodeSelector:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.affinity }}
+      affinity:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.tolerations }}
+      tolerations:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+{{- end }}`,
+
+	"helm/templates/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "app.fullname" . }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+    app.kubernetes.io/component: api
+spec:
+  type: {{ .Values.service.type }}
+  selector:
+    {{- include "app.selectorLabels" . | nindent 4 }}
+    app.kubernetes.io/component: api
+  ports:
+    - name: http
+      port: {{ .Values.service.port }}
+      targetPort: http`,
+
+	"helm/templates/hpa.yaml": `{{- if .Values.autoscaling.enabled }}
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ include "app.fullname" . }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+    app.kubernetes.io/component: api
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{ include "app.fullname" . }}
+  minReplicas: {{ .Values.autoscaling.minReplicas }}
+  maxReplicas: {{ .Values.autoscaling.maxReplicas }}
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: {{ .Values.autoscaling.targetCPUUtilizationPercentage }}
+{{- end }}`,
+
+	"helm/templates/NOTES.txt": `{{ include "app.fullname" . }} is deployed. Try it with:
+
+  kubectl --namespace {{ .Release.Namespace }} port-forward service/{{ include "app.fullname" . }} 8080:{{ .Values.service.port }}
+  curl http://localhost:8080/health
+{{- if and (not .Values.existingSecret) (has "" (values .Values.secrets)) }}
+
+Some secrets are empty, so the server won't start until they are set:
+{{- range $key, $value := .Values.secrets }}
+{{- if not $value }}
+  --set secrets.{{ $key }}=...
+{{- end }}
+{{- end }}
+{{- end }}`,
+
+	"helm/chart_test.go": `{{- $env := envPrefix .ProjectName -}}
+package helm_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/applyconfigurations"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+const release = "test"
+
+// render runs 'helm template' on the chart, with overrides passed as an
+// extra values file, and decodes the objects it prints. The test is skipped
+// when helm is not installed.
+func render(t *testing.T, overrides map[string]any) []*unstructured.Unstructured {
+	t.Helper()
+	helm, err := exec.LookPath("helm")
+	if err != nil {
+		t.Skip("helm is not installed")
+	}
+
+	args := []string{"template", release, "."}
+	if overrides != nil {
+		content, err := yaml.Marshal(overrides)
+		require.NoError(t, err)
+		file := filepath.Join(t.TempDir(), "values.yaml")
+		require.NoError(t, os.WriteFile(file, content, 0644))
+		args = append(args, "--values", file)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(helm, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	require.NoError(t, err, "helm template: %s", stderr.String())
+	return decode(t, "helm template", string(out))
+}
+
+func readYAML(t *testing.T, file string, into any) {
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(content, into), file)
+}
+
+// decode splits a rendered file into its objects.
+func decode(t *testing.T, file, rendered string) []*unstructured.Unstructured {
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(rendered), 4096)
+	for {
+		var object map[string]any
+		err := decoder.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err, "%s rendered invalid YAML:\n%s", file, rendered)
+		if object != nil {
+			objects = append(objects, &unstructured.Unstructured{Object: object})
+		}
+	}
+	return objects
+}
+
+// find returns the object of kind named name, decoded into into.
+func find(t *testing.T, objects []*unstructured.Unstructured, kind, name string, into any) {
+	for _, object := range objects {
+		if object.GetKind() == kind && object.GetName() == name {
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, into))
+			return
+		}
+	}
+	t.Fatalf("no %s named %s", kind, name)
+}
+
+func kinds(objects []*unstructured.Unstructured) []string {
+	var kinds []string
+	for _, object := range objects {
+		kinds = append(kinds, object.GetKind())
+	}
+	return kinds
+}
+
+// TestChart_MatchesSchemas renders the chart with its default values and
+// with the optional parts switched, and checks every object against the
+// OpenAPI schemas of the Kubernetes API, which client-go embeds, so no
+// cluster or network is needed. Unknown fields and values of the wrong type
+// fail the test.
+func TestChart_MatchesSchemas(t *testing.T) {
+	converter := applyconfigurations.NewTypeConverter(scheme.Scheme)
+
+	tests := map[string]map[string]any{
+		"defaults": nil,
+		"without autoscaling, with an existing secret": {
+			"autoscaling":    map[string]any{"enabled": false},
+			"existingSecret": "my-secrets",
+		},
+		"with scheduling": {
+			"nodeSelector":     map[string]any{"kubernetes.io/os": "linux"},
+			"tolerations":      []any{map[string]any{"key": "dedicated", "operator": "Exists", "effect": "NoSchedule"}},
+			"imagePullSecrets": []any{map[string]any{"name": "registry"}},
+			"podAnnotations":   map[string]any{"example.com/team": "api"},
+		},
+	}
+
+	for name, overrides := range tests {
+		t.Run(name, func(t *testing.T) {
+			objects := render(t, overrides)
+			require.NotEmpty(t, objects)
+			for _, object := range objects {
+				_, err := converter.ObjectToTyped(object)
+				assert.NoError(t, err, "%s %s", object.GetKind(), object.GetName())
+			}
+		})
+	}
+}
+
+func TestChart_OptionalObjects(t *testing.T) {
+	assert.Contains(t, kinds(render(t, nil)), "HorizontalPodAutoscaler")
+	assert.Contains(t, kinds(render(t, nil)), "Secret")
+
+	objects := render(t, map[string]any{
+		"autoscaling":    map[string]any{"enabled": false},
+		"existingSecret": "my-secrets",
+	})
+	assert.NotContains(t, kinds(objects), "HorizontalPodAutoscaler")
+	assert.NotContains(t, kinds(objects), "Secret")
+
+	var deployment appsv1.Deployment
+	find(t, objects, "Deployment", fullname(t), &deployment)
+	require.NotNil(t, deployment.Spec.Replicas)
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].EnvFrom,
+		corev1.EnvFromSource{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "my-secrets"}}})
+}
+
+func TestChart_ProbesHealthEndpoint(t *testing.T) {
+	var deployment appsv1.Deployment
+	find(t, render(t, nil), "Deployment", fullname(t), &deployment)
+	container := deployment.Spec.Template.Spec.Containers[0]
+
+	for name, probe := range map[string]*corev1.Probe{
+		"startup":   container.StartupProbe,
+		"liveness":  container.LivenessProbe,
+		"readiness": container.ReadinessProbe,
+	} {
+		require.NotNil(t, probe, name)
+		require.NotNil(t, probe.HTTPGet, name)
+		assert.Equal(t, "/health", probe.HTTPGet.Path, name)
+		assert.Equal(t, "http", probe.HTTPGet.Port.String(), name)
+	}
+	assert.Equal(t, int32(8080), container.Ports[0].ContainerPort)
+}
+
+func TestChart_IsWiredTogether(t *testing.T) {
+	objects := render(t, nil)
+	var deployment appsv1.Deployment
+	find(t, objects, "Deployment", fullname(t), &deployment)
+	podLabels := deployment.Spec.Template.Labels
+
+	var service corev1.Service
+	find(t, objects, "Service", fullname(t), &service)
+	for key, value := range service.Spec.Selector {
+		assert.Equal(t, value, podLabels[key], "service selector label %s", key)
+	}
+	// The Service sends traffic to a port the container declares
+	var portNames []string
+	for _, port := range deployment.Spec.Template.Spec.Containers[0].Ports {
+		portNames = append(portNames, port.Name)
+	}
+	for _, port := range service.Spec.Ports {
+		assert.Contains(t, portNames, port.TargetPort.String())
+	}
+
+	var configMap corev1.ConfigMap
+	find(t, objects, "ConfigMap", fullname(t)+"-config", &configMap)
+	var secret corev1.Secret
+	find(t, objects, "Secret", fullname(t)+"-secrets", &secret)
+	for _, source := range deployment.Spec.Template.Spec.Containers[0].EnvFrom {
+		switch {
+		case source.ConfigMapRef != nil:
+			assert.Equal(t, configMap.Name, source.ConfigMapRef.Name)
+		case source.SecretRef != nil:
+			assert.Equal(t, secret.Name, source.SecretRef.Name)
+		}
+	}
+	assert.Equal(t, "8080", configMap.Data["{{$env}}_SERVER_PORT"])
+
+	// Only the application's own variables, so none is mistyped
+	for key := range configMap.Data {
+		assert.True(t, key == "APP_ENV" || strings.HasPrefix(key, "{{$env}}_"), "unexpected variable %s", key)
+	}
+	for key := range secret.StringData {
+		assert.True(t, strings.HasPrefix(key, "{{$env}}_"), "unexpected variable %s", key)
+	}
+}
+
+// fullname is the name the chart gives its objects in the test release.
+func fullname(t *testing.T) string {
+	var chart struct {
+		Name string ` + "`" + `json:"name"` + "`" + `
+	}
+	readYAML(t, "Chart.yaml", &chart)
+	if strings.Contains(release, chart.Name) {
+		return release
+	}
+	return fmt.Sprintf("%s-%s", release, chart.Name)
+}`,
+}
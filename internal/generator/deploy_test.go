@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResourceName(t *testing.T) {
+	tests := map[string]string{
+		"myapp":                        "myapp",
+		"github.com/acme/my-shop":      "my-shop",
+		"github.com/acme/My_Shop":      "my-shop",
+		"HTTPServer":                   "http-server",
+		"9lives":                       "app-9lives",
+		"héllo":                        "hllo",
+		"":                             "app",
+		"___":                          "app",
+		strings.Repeat("a", 62) + "_b": strings.Repeat("a", 62),
+		strings.Repeat("x", 80):        strings.Repeat("x", 63),
+	}
+	for module, want := range tests {
+		if got := resourceName(module); got != want {
+			t.Errorf("resourceName(%q) = %q, want %q", module, got, want)
+		}
+	}
+}
+
+// deployProject generates a project with tests and moves into it. Modules
+// can't be downloaded, so requirements are only written to go.mod.
+func deployProject(t *testing.T) {
+	t.Helper()
+	work := sandbox(t)
+	t.Setenv("GOPROXY", "off")
+	if err := GenerateProject("myapp"); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(work, "myapp"))
+}
+
+func TestGenerateDeployTarget(t *testing.T) {
+	deployProject(t)
+
+	err := GenerateDeploy("nomad", false, false)
+	if err == nil || !strings.Contains(err.Error(), `unsupported deploy target "nomad"`) {
+		t.Fatalf("GenerateDeploy(nomad) error = %v", err)
+	}
+	if isDir("deploy") {
+		t.Error("an unsupported target wrote files")
+	}
+}
+
+func TestGenerateDeployKubernetes(t *testing.T) {
+	deployProject(t)
+
+	if err := GenerateDeploy("k8s", false, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"kustomization.yaml", "deployment.yaml", "service.yaml", "manifests_test.go"} {
+		if _, err := os.Stat(filepath.Join("deploy", "k8s", name)); err != nil {
+			t.Errorf("deploy/k8s/%s was not generated: %v", name, err)
+		}
+	}
+	if isDir(filepath.Join("deploy", "helm")) {
+		t.Error("the Helm chart was generated without --helm")
+	}
+
+	manifest, err := LoadManifest(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !manifest.Features.Kubernetes || manifest.Features.Helm {
+		t.Errorf("features = %+v, want only kubernetes", manifest.Features)
+	}
+	goMod := readFile(t, "go.mod")
+	for _, module := range []string{"k8s.io/api", "k8s.io/client-go", "sigs.k8s.io/yaml"} {
+		if !strings.Contains(goMod, module+" ") {
+			t.Errorf("go.mod doesn't require %s, which the manifest tests import", module)
+		}
+	}
+
+	// Existing files are only replaced with force
+	deployment := filepath.Join("deploy", "k8s", "deployment.yaml")
+	edited := readFile(t, deployment) + "# tuned by hand\n"
+	if err := os.WriteFile(deployment, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = GenerateDeploy("k8s", false, false)
+	if err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Fatalf("regenerating without force: %v, want a --force error", err)
+	}
+	if readFile(t, deployment) != edited {
+		t.Fatal("the refused generation rewrote deployment.yaml")
+	}
+	if err := GenerateDeploy("k8s", false, true); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, deployment) == edited {
+		t.Error("force did not regenerate deployment.yaml")
+	}
+}
+
+func TestGenerateDeployHelm(t *testing.T) {
+	deployProject(t)
+
+	if err := GenerateDeploy("k8s", true, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Chart.yaml", "values.yaml", "templates/deployment.yaml", "chart_test.go"} {
+		if _, err := os.Stat(filepath.Join("deploy", "helm", filepath.FromSlash(name))); err != nil {
+			t.Errorf("deploy/helm/%s was not generated: %v", name, err)
+		}
+	}
+	if isDir(filepath.Join("deploy", "k8s")) {
+		t.Error("plain manifests were generated with --helm")
+	}
+
+	manifest, err := LoadManifest(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !manifest.Features.Helm || manifest.Features.Kubernetes {
+		t.Errorf("features = %+v, want only helm", manifest.Features)
+	}
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GoVersion is the Go release generated projects target. It sets the go
+// directive in go.mod, the Docker builder image and the minimum 'doctor'
+// accepts.
+const GoVersion = "1.24"
+
+// Images used by the generated Dockerfile and docker-compose.yml.
+const (
+	BuilderImage  = "golang:" + GoVersion + "-alpine"
+	RuntimeImage  = "gcr.io/distroless/static-debian12:nonroot"
+	PostgresImage = "postgres:17-alpine"
+	MySQLImage    = "mysql:8.4"
+	RedisImage    = "redis:7-alpine"
+)
+
+// DBDrivers lists the databases generated projects can use. The catalogue
+// has a gorm driver for each, and the generated code connects only to these.
+var DBDrivers = []string{"postgres", "mysql"}
+
+// ValidateDBDriver reports an error for databases generated projects can't
+// connect to.
+func ValidateDBDriver(driver string) error {
+	for _, d := range DBDrivers {
+		if driver == d {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported database driver %q (supported: %s)", driver, strings.Join(DBDrivers, ", "))
+}
+
+// LintVersion is the golangci-lint release generated CI pipelines run.
+const LintVersion = "v2.1.6"
+
+// Dependency is a module required by generated projects.
+type Dependency struct {
+	Module  string
+	Version string
+}
+
+type catalogueEntry struct {
+	Dependency
+	// when reports whether the project's features need the module; nil
+	// means always.
+	when func(ProjectData) bool
+}
+
+// dependencyCatalogue is the single source of the module versions written
+// to go.mod. Keep it sorted by module path.
+var dependencyCatalogue = []catalogueEntry{
+	{Dependency: Dependency{"github.com/alicebob/miniredis/v2", "v2.35.0"}, when: func(d ProjectData) bool { return (d.WithRateLimit || d.WithCache) && d.WithTests }},
+	{Dependency: Dependency{"github.com/gin-gonic/gin", "v1.10.1"}},
+	{Dependency: Dependency{"github.com/joho/godotenv", "v1.5.1"}},
+	{Dependency: Dependency{"github.com/prometheus/client_golang", "v1.22.0"}, when: func(d ProjectData) bool { return d.WithMetrics }},
+	{Dependency: Dependency{"github.com/redis/go-redis/v9", "v9.11.0"}, when: func(d ProjectData) bool { return d.WithRateLimit || d.WithCache }},
+	{Dependency: Dependency{"github.com/spf13/viper", "v1.20.1"}},
+	{Dependency: Dependency{"github.com/stretchr/testify", "v1.10.0"}, when: func(d ProjectData) bool { return d.WithTests }},
+	{Dependency: Dependency{"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin", "v0.61.0"}, when: func(d ProjectData) bool { return d.WithTracing }},
+	{Dependency: Dependency{"go.opentelemetry.io/otel", "v1.36.0"}, when: func(d ProjectData) bool { return d.WithTracing }},
+	{Dependency: Dependency{"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp", "v1.36.0"}, when: func(d ProjectData) bool { return d.WithTracing }},
+	{Dependency: Dependency{"go.opentelemetry.io/otel/sdk", "v1.36.0"}, when: func(d ProjectData) bool { return d.WithTracing }},
+	{Dependency: Dependency{"go.opentelemetry.io/otel/trace", "v1.36.0"}, when: func(d ProjectData) bool { return d.WithTracing }},
+	{Dependency: Dependency{"golang.org/x/sync", "v0.14.0"}, when: func(d ProjectData) bool { return d.WithCache }},
+	{Dependency: Dependency{"gorm.io/driver/mysql", "v1.6.0"}, when: func(d ProjectData) bool { return d.DBDriver == "mysql" }},
+	{Dependency: Dependency{"gorm.io/driver/postgres", "v1.6.0"}, when: func(d ProjectData) bool { return d.DBDriver == "postgres" }},
+	{Dependency: Dependency{"gorm.io/gorm", "v1.30.0"}},
+	{Dependency: Dependency{"gorm.io/plugin/opentelemetry", "v0.1.16"}, when: func(d ProjectData) bool { return d.WithTracing }},
+	{Dependency: Dependency{"k8s.io/api", "v0.34.1"}, when: func(d ProjectData) bool { return (d.WithKubernetes || d.WithHelm) && d.WithTests }},
+	{Dependency: Dependency{"k8s.io/apimachinery", "v0.34.1"}, when: func(d ProjectData) bool { return (d.WithKubernetes || d.WithHelm) && d.WithTests }},
+	{Dependency: Dependency{"k8s.io/client-go", "v0.34.1"}, when: func(d ProjectData) bool { return (d.WithKubernetes || d.WithHelm) && d.WithTests }},
+	{Dependency: Dependency{"sigs.k8s.io/yaml", "v1.6.0"}, when: func(d ProjectData) bool { return (d.WithKubernetes || d.WithHelm) && d.WithTests }},
+}
+
+// projectDependencies returns the modules a project with the given features
+// requires, in catalogue order.
+func projectDependencies(data ProjectData) []Dependency {
+	var deps []Dependency
+	for _, entry := range dependencyCatalogue {
+		if entry.when == nil || entry.when(data) {
+			deps = append(deps, entry.Dependency)
+		}
+	}
+	return deps
+}
+
+// withCatalogue fills in the toolchain and dependency fields of data, and
+// the placeholder secrets the generated config refuses.
+func (d ProjectData) withCatalogue() ProjectData {
+	d.GoVersion = GoVersion
+	d.BuilderImage = BuilderImage
+	d.RuntimeImage = RuntimeImage
+	d.DatabaseImage = PostgresImage
+	if d.DBDriver == "mysql" {
+		d.DatabaseImage = MySQLImage
+	}
+	d.RedisImage = RedisImage
+	d.PlaceholderSecrets = PlaceholderSecrets
+	d.LintVersion = LintVersion
+	d.Dependencies = projectDependencies(d)
+	return d
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// secretSuffixes mark the .env keys that hold secrets.
+var secretSuffixes = []string{"_SECRET", "_PASSWORD", "_TOKEN"}
+
+// PlaceholderSecrets are example values, such as the ones .env.example ships
+// with, that generated projects refuse in release mode and doctor reports.
+var PlaceholderSecrets = []string{
+	"your-super-secret-jwt-key-here",
+	"password",
+	"secret",
+	"changeme",
+	"postgres",
+	"root",
+	"admin",
+}
+
+// IsPlaceholderSecret reports whether s is one of PlaceholderSecrets,
+// ignoring case like the generated projects do.
+func IsPlaceholderSecret(s string) bool {
+	for _, placeholder := range PlaceholderSecrets {
+		if strings.EqualFold(s, placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// RandomSecret returns a random 256-bit secret, hex encoded.
+func RandomSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// writeDevEnv creates .env from the rendered .env.example, replacing the
+// example value of every secret with a random one so no two projects share
+// them. Secrets left empty in the example stay empty, as do all values when
+// the project has no .env.example. An existing .env is kept. It reports
+// whether .env was written.
+func writeDevEnv(dest string, files map[string][]byte) (bool, error) {
+	example, ok := files[".env.example"]
+	if !ok {
+		return false, nil
+	}
+	path := filepath.Join(dest, ".env")
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	lines := strings.Split(string(example), "\n")
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || value == "" || strings.HasPrefix(strings.TrimSpace(key), "#") || !isSecretKey(key) {
+			continue
+		}
+		secret, err := RandomSecret()
+		if err != nil {
+			return false, err
+		}
+		lines[i] = key + "=" + secret
+	}
+
+	// .env holds secrets, so only the owner may read it
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func isSecretKey(key string) bool {
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// renderFile renders a template and, when the output is a Go file, prunes
+// unused imports and formats it like gofmt. Rendered code that doesn't parse
+// is reported with its file and line instead of being written.
+func renderFile(templateName, outputPath string, content []byte, data any) ([]byte, error) {
+	rendered, err := renderTemplate(templateName, content, data)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(outputPath, ".go") {
+		return rendered, nil
+	}
+
+	formatted, err := formatGoSource(outputPath, rendered)
+	if err != nil {
+		return nil, fmt.Errorf("template %s rendered invalid Go:\n%w", templateName, err)
+	}
+	return formatted, nil
+}
+
+// formatGoSource removes imports the file doesn't reference and formats it.
+func formatGoSource(filename string, src []byte) ([]byte, error) {
+	pruned, err := pruneImports(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source(pruned)
+	if err != nil {
+		return nil, sourceError(filename, pruned, err)
+	}
+	return formatted, nil
+}
+
+// pruneImports drops import lines whose package is never referenced. Blank
+// and dot imports are always kept.
+func pruneImports(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, sourceError(filename, src, err)
+	}
+
+	used := map[string]bool{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			// Package qualifiers are the identifiers the parser can't resolve
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var drop []lineRange
+	tokFile := fset.File(file.Pos())
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		unused := 0
+		var specs []lineRange
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if isUsedImport(imp, used) {
+				continue
+			}
+			unused++
+			specs = append(specs, lineSpan(tokFile, src, imp.Pos(), imp.End()))
+		}
+
+		if unused > 0 && unused == len(gen.Specs) {
+			drop = append(drop, lineSpan(tokFile, src, gen.Pos(), gen.End()))
+			continue
+		}
+		drop = append(drop, specs...)
+	}
+
+	if len(drop) == 0 {
+		return src, nil
+	}
+
+	// Cut from the end so earlier offsets stay valid
+	out := append([]byte(nil), src...)
+	for i := len(drop) - 1; i >= 0; i-- {
+		out = append(out[:drop[i].start], out[drop[i].end:]...)
+	}
+	return out, nil
+}
+
+func isUsedImport(imp *ast.ImportSpec, used map[string]bool) bool {
+	if imp.Name != nil {
+		switch imp.Name.Name {
+		case "_", ".":
+			return true
+		}
+		return used[imp.Name.Name]
+	}
+	importPath, err := strconv.Unquote(imp.Path.Value)
+	if err != nil {
+		return true
+	}
+	return used[importPackageName(importPath)]
+}
+
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// importPackageName guesses the package name of an import path the way
+// goimports does without loading the package: the last element, skipping
+// major version suffixes and go-/.vN decorations.
+func importPackageName(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	if dot := strings.Index(name, "."); dot > 0 {
+		name = name[:dot]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// lineRange is a byte range of src covering whole lines.
+type lineRange struct{ start, end int }
+
+// lineSpan returns the whole lines covering pos to end.
+func lineSpan(file *token.File, src []byte, pos, end token.Pos) lineRange {
+	start := file.Offset(file.LineStart(file.Line(pos)))
+	stop := file.Offset(end)
+	if nl := bytes.IndexByte(src[stop:], '\n'); nl >= 0 {
+		stop += nl + 1
+	} else {
+		stop = len(src)
+	}
+	return lineRange{start, stop}
+}
+
+// sourceError reports the first syntax error as file:line:col and quotes
+// the offending line.
+func sourceError(filename string, src []byte, err error) error {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	first := list[0]
+	msg := fmt.Sprintf("%s:%d:%d: %s", filename, first.Pos.Line, first.Pos.Column, first.Msg)
+	lines := strings.Split(string(src), "\n")
+	if first.Pos.Line > 0 && first.Pos.Line <= len(lines) {
+		msg += fmt.Sprintf("\n    %d | %s", first.Pos.Line, lines[first.Pos.Line-1])
+	}
+	if len(list) > 1 {
+		msg += fmt.Sprintf("\n    (and %d more errors)", len(list)-1)
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportPackageName(t *testing.T) {
+	tests := map[string]string{
+		"fmt":                                    "fmt",
+		"net/http":                               "http",
+		"gorm.io/gorm":                           "gorm",
+		"gopkg.in/yaml.v3":                       "yaml",
+		"github.com/redis/go-redis/v9":           "redis",
+		"github.com/go-playground/validator/v10": "validator",
+		"github.com/mattn/go-sqlite3":            "sqlite3",
+		"github.com/acme/rate-limit":             "ratelimit",
+		"go.opentelemetry.io/otel":               "otel",
+		"k8s.io/client-go/kubernetes/scheme":     "scheme",
+	}
+	for importPath, want := range tests {
+		if got := importPackageName(importPath); got != want {
+			t.Errorf("importPackageName(%q) = %q, want %q", importPath, got, want)
+		}
+	}
+}
+
+func TestPruneImports(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "renamed imports follow their name",
+			src: `package p
+
+import (
+	ginpkg "github.com/gin-gonic/gin"
+	stdhttp "net/http"
+)
+
+var _ = ginpkg.New
+`,
+			want: `package p
+
+import (
+	ginpkg "github.com/gin-gonic/gin"
+)
+
+var _ = ginpkg.New
+`,
+		},
+		{
+			name: "major version suffixes",
+			src: `package p
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = redis.NewClient
+`,
+			want: `package p
+
+import (
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = redis.NewClient
+`,
+		},
+		{
+			name: "dotted and gopkg.in paths",
+			src: `package p
+
+import (
+	"go.opentelemetry.io/otel"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = yaml.Marshal
+`,
+			want: `package p
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+var _ = yaml.Marshal
+`,
+		},
+		{
+			name: "blank and dot imports are kept",
+			src: `package p
+
+import (
+	"fmt"
+	. "strings"
+	_ "github.com/lib/pq"
+)
+`,
+			want: `package p
+
+import (
+	. "strings"
+	_ "github.com/lib/pq"
+)
+`,
+		},
+		{
+			name: "a local variable is not a package reference",
+			src: `package p
+
+import "encoding/json"
+
+func f(json struct{ Valid bool }) bool {
+	return json.Valid
+}
+`,
+			// The blank line left behind is removed by gofmt
+			want: `package p
+
+
+func f(json struct{ Valid bool }) bool {
+	return json.Valid
+}
+`,
+		},
+		{
+			name: "single import declarations",
+			src: `package p
+
+import "fmt"
+import "os"
+
+var _ = os.Exit
+`,
+			want: `package p
+
+import "os"
+
+var _ = os.Exit
+`,
+		},
+		{
+			name: "used imports are left alone",
+			src: `package p
+
+import "fmt"
+
+var _ = fmt.Sprint
+`,
+			want: `package p
+
+import "fmt"
+
+var _ = fmt.Sprint
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pruneImports("p.go", []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("pruneImports() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatGoSource(t *testing.T) {
+	src := "package p\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\nfunc F( ) string {\nreturn fmt.Sprint(1)\n}\n"
+	got, err := formatGoSource("p.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package p\n\nimport (\n\t\"fmt\"\n)\n\nfunc F() string {\n\treturn fmt.Sprint(1)\n}\n"
+	if string(got) != want {
+		t.Errorf("formatGoSource() =\n%s\nwant\n%s", got, want)
+	}
+
+	_, err = formatGoSource("p.go", []byte("package p\n\nfunc F() {\n\treturn 1 +\n}\n"))
+	if err == nil {
+		t.Fatal("formatGoSource accepted invalid Go")
+	}
+	if !strings.HasPrefix(err.Error(), "p.go:5:1: ") || !strings.Contains(err.Error(), "\n    5 | }") {
+		t.Errorf("error = %q, want the position and the offending line", err)
+	}
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adipras/lupettogo/internal/output"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// testdataDir is absolute because sandbox changes the working directory.
+var testdataDir string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	wd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	testdataDir = filepath.Join(wd, "testdata")
+	if err := output.Configure(output.FormatText, true, true); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// sandbox runs the test in an empty working directory with its own config
+// directory, so user templates and the pack cache of the machine running the
+// tests are never read or written.
+func sandbox(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	// Keep the go command from starting telemetry uploads into the config
+	// directory while the test cleans it up
+	writeTree(t, dir, map[string]string{"config/go/telemetry/mode": "off\n"})
+	work := filepath.Join(dir, "work")
+	if err := os.MkdirAll(work, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(work)
+	return work
+}
+
+// writeTree writes files, keyed by slash-separated path, below dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// assertGolden compares got with testdata/name, rewriting it with -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join(testdataDir, name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s is out of date, run go test -update and review the diff\n--- got ---\n%s", path, got)
+	}
+}
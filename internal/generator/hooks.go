@@ -0,0 +1,274 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/adipras/lupettogo/internal/output"
+)
+
+// HookOptions selects the steps run after a project is generated.
+type HookOptions struct {
+	Git    bool
+	Tidy   bool
+	Vendor bool
+
+	// Offline skips steps that need the network, such as go mod tidy.
+	Offline bool
+	// PackHooks runs the commands declared by the template pack. They run
+	// code from wherever the pack came from, so they are skipped unless the
+	// user agreed to run them.
+	PackHooks bool
+}
+
+// PackHook is a command a template pack runs in the new project.
+type PackHook struct {
+	Name    string `yaml:"name"`
+	Run     string `yaml:"run"`
+	Timeout string `yaml:"timeout"`
+	Network bool   `yaml:"network"`
+}
+
+const (
+	HookSucceeded = "ok"
+	HookFailed    = "failed"
+	HookSkipped   = "skipped"
+)
+
+// HookResult is the outcome of one post-generation step.
+type HookResult struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Reason   string        `json:"reason,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+type hookStep struct {
+	name     string
+	commands [][]string
+	env      []string
+	timeout  time.Duration
+	network  bool
+	// skip is why the step is reported as skipped instead of run.
+	skip string
+}
+
+const (
+	defaultHookTimeout = 5 * time.Minute
+	gitHookTimeout     = time.Minute
+)
+
+// postGenerateSteps lists the steps to run, in order. Git comes last so the
+// initial commit includes go.sum, vendor/ and whatever the pack hooks made.
+func postGenerateSteps(opts HookOptions, pack *TemplatePack) ([]hookStep, error) {
+	var steps []hookStep
+
+	if opts.Tidy {
+		steps = append(steps, hookStep{
+			name:     "go mod tidy",
+			commands: [][]string{{"go", "mod", "tidy"}},
+			timeout:  defaultHookTimeout,
+			network:  true,
+		})
+	}
+	if opts.Vendor {
+		steps = append(steps, hookStep{
+			name:     "go mod vendor",
+			commands: [][]string{{"go", "mod", "vendor"}},
+			timeout:  defaultHookTimeout,
+			network:  true,
+		})
+	}
+
+	if pack != nil {
+		for _, hook := range pack.Hooks {
+			if !opts.PackHooks {
+				steps = append(steps, hookStep{name: hook.Name, skip: "template pack hooks need confirmation or --pack-hooks"})
+				continue
+			}
+			timeout := defaultHookTimeout
+			if hook.Timeout != "" {
+				parsed, err := time.ParseDuration(hook.Timeout)
+				if err != nil {
+					return nil, fmt.Errorf("template pack hook %q has an invalid timeout: %w", hook.Name, err)
+				}
+				timeout = parsed
+			}
+			steps = append(steps, hookStep{
+				name:     hook.Name,
+				commands: [][]string{ShellCommand(hook.Run)},
+				timeout:  timeout,
+				network:  hook.Network,
+			})
+		}
+	}
+
+	if opts.Git {
+		steps = append(steps, hookStep{
+			name: "git init",
+			commands: [][]string{
+				{"git", "init", "--quiet"},
+				{"git", "add", "--all"},
+				{"git", "commit", "--quiet", "-m", "Initial commit from lupettogo " + Version},
+			},
+			env:     gitIdentityEnv(),
+			timeout: gitHookTimeout,
+		})
+	}
+
+	return steps, nil
+}
+
+// gitIdentityEnv supplies a committer for the initial commit on machines
+// where git has no user configured, such as CI runners and containers.
+func gitIdentityEnv() []string {
+	if email, err := runGit("", "config", "user.email"); err == nil && email != "" {
+		return nil
+	}
+	return []string{
+		"GIT_AUTHOR_NAME=lupettogo", "GIT_AUTHOR_EMAIL=lupettogo@localhost",
+		"GIT_COMMITTER_NAME=lupettogo", "GIT_COMMITTER_EMAIL=lupettogo@localhost",
+	}
+}
+
+// ShellCommand returns the command line running script in the platform
+// shell.
+func ShellCommand(script string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", script}
+	}
+	return []string{"sh", "-c", script}
+}
+
+// RunPostGenerateHooks runs the selected steps in dir, streaming their
+// output. A failing step doesn't stop the ones after it; the results say
+// what happened to each.
+func RunPostGenerateHooks(dir string, opts HookOptions, pack *TemplatePack) ([]HookResult, error) {
+	steps, err := postGenerateSteps(opts, pack)
+	if err != nil {
+		return nil, err
+	}
+
+	offline := opts.Offline || proxyOff()
+	results := make([]HookResult, 0, len(steps))
+
+	for _, step := range steps {
+		if step.skip != "" {
+			results = append(results, HookResult{Name: step.name, Status: HookSkipped, Reason: step.skip})
+			continue
+		}
+		if step.network && offline {
+			results = append(results, HookResult{Name: step.name, Status: HookSkipped, Reason: "offline"})
+			continue
+		}
+
+		output.Printf("▶️  %s\n", step.name)
+		start := time.Now()
+		err := runHookStep(dir, step)
+		result := HookResult{Name: step.name, Status: HookSucceeded, Duration: time.Since(start)}
+		if err != nil {
+			result.Status = HookFailed
+			result.Reason = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func runHookStep(dir string, step hookStep) error {
+	ctx, cancel := context.WithTimeout(context.Background(), step.timeout)
+	defer cancel()
+
+	for _, args := range step.commands {
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = dir
+		cmd.Stdout = output.Stream()
+		cmd.Stderr = output.Stream()
+		cmd.Env = append(os.Environ(), "LUPETTOGO_VERSION="+Version)
+		cmd.Env = append(cmd.Env, step.env...)
+
+		if err := cmd.Run(); err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("timed out after %s", step.timeout)
+			}
+			if errors.Is(err, exec.ErrNotFound) {
+				return fmt.Errorf("%s is not installed", args[0])
+			}
+			return fmt.Errorf("%s: %w", strings.Join(args, " "), err)
+		}
+	}
+	return nil
+}
+
+// PrintHookResults prints a summary of the post-generation steps.
+func PrintHookResults(results []HookResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	output.Println("🪝 Post-generation steps:")
+	for _, result := range results {
+		switch result.Status {
+		case HookSucceeded:
+			output.Printf("   ✅ %s (%s)\n", result.Name, result.Duration.Round(time.Millisecond))
+		case HookSkipped:
+			output.Printf("   ⏭️  %s skipped: %s\n", result.Name, result.Reason)
+		default:
+			output.Printf("   ❌ %s failed: %s\n", result.Name, result.Reason)
+		}
+	}
+}
+
+// hookSucceeded reports whether the named step ran successfully.
+func hookSucceeded(results []HookResult, name string) bool {
+	for _, result := range results {
+		if result.Name == name {
+			return result.Status == HookSucceeded
+		}
+	}
+	return false
+}
+
+// proxyOff reports whether GOPROXY=off forbids downloading modules.
+func proxyOff() bool {
+	return os.Getenv("GOPROXY") == "off"
+}
+
+// requireDependencies adds deps to the go.mod of the project in dir with
+// 'go get', which fills in go.sum as well. When modules can't be downloaded
+// the requirements are written with 'go mod edit' instead, and it reports
+// that 'go mod tidy' still has to run.
+func requireDependencies(dir string, deps []Dependency) (bool, error) {
+	if len(deps) == 0 {
+		return true, nil
+	}
+	args := dependencyArgs(deps)
+
+	if !proxyOff() {
+		output.Printf("▶️  go get %s\n", strings.Join(args, " "))
+		err := runHookStep(dir, hookStep{
+			commands: [][]string{append([]string{"go", "get"}, args...)},
+			timeout:  defaultHookTimeout,
+		})
+		if err == nil {
+			return true, nil
+		}
+		output.Warnf("go get failed, adding the requirements to go.mod only: %v", err)
+	}
+
+	edit := []string{"go", "mod", "edit"}
+	for _, arg := range args {
+		edit = append(edit, "-require="+arg)
+	}
+	if err := runHookStep(dir, hookStep{commands: [][]string{edit}, timeout: gitHookTimeout}); err != nil {
+		return false, fmt.Errorf("failed to add %s to go.mod: %w", strings.Join(args, " "), err)
+	}
+	return false, nil
+}
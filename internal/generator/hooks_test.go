@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequireDependenciesOffline(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	work := sandbox(t)
+	t.Setenv("GOPROXY", "off")
+	writeTree(t, work, map[string]string{"go.mod": "module example.com/app\n\ngo 1.24\n"})
+
+	deps := []Dependency{{"github.com/redis/go-redis/v9", "v9.11.0"}, {"golang.org/x/sync", "v0.14.0"}}
+	downloaded, err := requireDependencies(work, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if downloaded {
+		t.Error("requireDependencies reported downloads with GOPROXY=off")
+	}
+
+	goMod := readFile(t, filepath.Join(work, "go.mod"))
+	for _, dep := range deps {
+		if !strings.Contains(goMod, dep.Module+" "+dep.Version) {
+			t.Errorf("go.mod doesn't require %s %s:\n%s", dep.Module, dep.Version, goMod)
+		}
+	}
+
+	if downloaded, err := requireDependencies(work, nil); err != nil || !downloaded {
+		t.Errorf("requireDependencies(nil) = %v, %v; want nothing to do", downloaded, err)
+	}
+}
+
+func TestPackHooksNeedOptIn(t *testing.T) {
+	work := sandbox(t)
+	pack := &TemplatePack{Name: "acme", Hooks: []PackHook{{Name: "touch", Run: "echo ran > ran.txt"}}}
+
+	results, err := RunPostGenerateHooks(work, HookOptions{}, pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != HookSkipped {
+		t.Fatalf("results = %+v, want the pack hook skipped", results)
+	}
+	if _, err := os.Stat(filepath.Join(work, "ran.txt")); !os.IsNotExist(err) {
+		t.Fatal("the pack hook ran without --pack-hooks")
+	}
+
+	results, err = RunPostGenerateHooks(work, HookOptions{PackHooks: true}, pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != HookSucceeded {
+		t.Fatalf("results = %+v, want the pack hook run", results)
+	}
+	if _, err := os.Stat(filepath.Join(work, "ran.txt")); err != nil {
+		t.Errorf("the opted-in pack hook didn't run: %v", err)
+	}
+}
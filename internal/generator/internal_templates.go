@@ -3,19 +3,43 @@ package generator
 // Internal package templates
 
 var internalTemplates = map[string]string{
-	"internal/config/config.go": `package config
+	"internal/config/config.go": `{{- $env := envPrefix .ProjectName -}}
+package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
+	// Env is the profile selected with APP_ENV
+	Env string ` + "`" + `mapstructure:"-"` + "`" + `
+
 	Server   ServerConfig   ` + "`" + `mapstructure:"server"` + "`" + `
 	Database DatabaseConfig ` + "`" + `mapstructure:"database"` + "`" + `
 	JWT      JWTConfig      ` + "`" + `mapstructure:"jwt"` + "`" + `
 	API      APIConfig      ` + "`" + `mapstructure:"api"` + "`" + `
+	CORS     CORSConfig     ` + "`" + `mapstructure:"cors"` + "`" + `
+{{- if .WithRateLimit}}
+	RateLimit RateLimitConfig ` + "`" + `mapstructure:"ratelimit"` + "`" + `
+	Redis     RedisConfig     ` + "`" + `mapstructure:"redis"` + "`" + `
+{{- end}}
+{{- if .WithMetrics}}
+	Metrics MetricsConfig ` + "`" + `mapstructure:"metrics"` + "`" + `
+{{- end}}
+{{- if .WithTracing}}
+	Tracing TracingConfig ` + "`" + `mapstructure:"tracing"` + "`" + `
+{{- end}}
+{{- if .WithJobs}}
+	Jobs JobsConfig ` + "`" + `mapstructure:"jobs"` + "`" + `
+{{- end}}
 }
 
 type ServerConfig struct {
@@ -27,13 +51,13 @@ type DatabaseConfig struct {
 	Host     string ` + "`" + `mapstructure:"host"` + "`" + `
 	Port     string ` + "`" + `mapstructure:"port"` + "`" + `
 	User     string ` + "`" + `mapstructure:"user"` + "`" + `
-	Password string ` + "`" + `mapstructure:"password"` + "`" + `
+	Password Secret ` + "`" + `mapstructure:"password"` + "`" + `
 	Name     string ` + "`" + `mapstructure:"name"` + "`" + `
 	Driver   string ` + "`" + `mapstructure:"driver"` + "`" + `
 }
 
 type JWTConfig struct {
-	Secret    string ` + "`" + `mapstructure:"secret"` + "`" + `
+	Secret    Secret ` + "`" + `mapstructure:"secret"` + "`" + `
 	ExpiresIn string ` + "`" + `mapstructure:"expires_in"` + "`" + `
 }
 
@@ -41,43 +65,369 @@ type APIConfig struct {
 	Version string ` + "`" + `mapstructure:"version"` + "`" + `
 }
 
+// CORSConfig is the cross-origin policy. Origins are matched exactly, or
+// with a leading wildcard label such as https://*.example.com; "*" allows
+// every origin but never with credentials.
+type CORSConfig struct {
+	AllowedOrigins   []string      ` + "`" + `mapstructure:"allowed_origins"` + "`" + `
+	AllowedMethods   []string      ` + "`" + `mapstructure:"allowed_methods"` + "`" + `
+	AllowedHeaders   []string      ` + "`" + `mapstructure:"allowed_headers"` + "`" + `
+	AllowCredentials bool          ` + "`" + `mapstructure:"allow_credentials"` + "`" + `
+	MaxAge           time.Duration ` + "`" + `mapstructure:"max_age"` + "`" + `
+}
+{{- if .WithRateLimit}}
+
+// RateLimitConfig is the default request rate allowed per client. Store is
+// memory or redis, and KeyBy ip, api_key or user.
+type RateLimitConfig struct {
+	Enabled      bool          ` + "`" + `mapstructure:"enabled"` + "`" + `
+	Store        string        ` + "`" + `mapstructure:"store"` + "`" + `
+	Requests     int           ` + "`" + `mapstructure:"requests"` + "`" + `
+	Period       time.Duration ` + "`" + `mapstructure:"period"` + "`" + `
+	Burst        int           ` + "`" + `mapstructure:"burst"` + "`" + `
+	KeyBy        string        ` + "`" + `mapstructure:"key_by"` + "`" + `
+	APIKeyHeader string        ` + "`" + `mapstructure:"api_key_header"` + "`" + `
+}
+
+type RedisConfig struct {
+	Addr     string ` + "`" + `mapstructure:"addr"` + "`" + `
+	Password Secret ` + "`" + `mapstructure:"password"` + "`" + `
+	DB       int    ` + "`" + `mapstructure:"db"` + "`" + `
+}
+{{- end}}
+{{- if .WithMetrics}}
+
+// MetricsConfig controls the admin port serving /metrics, and whether it
+// also serves /debug/pprof. Without a PprofToken, pprof only answers
+// requests from the local machine.
+type MetricsConfig struct {
+	Enabled    bool   ` + "`" + `mapstructure:"enabled"` + "`" + `
+	AdminPort  string ` + "`" + `mapstructure:"admin_port"` + "`" + `
+	Pprof      bool   ` + "`" + `mapstructure:"pprof"` + "`" + `
+	PprofToken Secret ` + "`" + `mapstructure:"pprof_token"` + "`" + `
+}
+{{- end}}
+{{- if .WithTracing}}
+
+// TracingConfig controls the OTLP/HTTP exporter spans are sent to, e.g. an
+// OpenTelemetry Collector or Jaeger. SampleRatio is the share of new traces
+// recorded; requests arriving with a sampled trace are always recorded.
+type TracingConfig struct {
+	Enabled     bool    ` + "`" + `mapstructure:"enabled"` + "`" + `
+	ServiceName string  ` + "`" + `mapstructure:"service_name"` + "`" + `
+	Endpoint    string  ` + "`" + `mapstructure:"endpoint"` + "`" + `
+	Insecure    bool    ` + "`" + `mapstructure:"insecure"` + "`" + `
+	SampleRatio float64 ` + "`" + `mapstructure:"sample_ratio"` + "`" + `
+}
+{{- end}}
+{{- if .WithJobs}}
+
+// JobsConfig controls the background worker. It runs up to Concurrency
+// jobs at once, checks the queue every PollInterval while it is empty and
+// gives each attempt Timeout to finish. A failed job waits Backoff before
+// its next attempt, doubling with every failure up to MaxBackoff, and is
+// dead-lettered after MaxAttempts.
+type JobsConfig struct {
+	Concurrency  int           ` + "`" + `mapstructure:"concurrency"` + "`" + `
+	PollInterval time.Duration ` + "`" + `mapstructure:"poll_interval"` + "`" + `
+	Timeout      time.Duration ` + "`" + `mapstructure:"timeout"` + "`" + `
+	MaxAttempts  int           ` + "`" + `mapstructure:"max_attempts"` + "`" + `
+	Backoff      time.Duration ` + "`" + `mapstructure:"backoff"` + "`" + `
+	MaxBackoff   time.Duration ` + "`" + `mapstructure:"max_backoff"` + "`" + `
+}
+{{- end}}
+
+// Profiles selected with APP_ENV. Each can have a config.<profile>.yaml
+// merged over config.yaml.
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+// EnvPrefix starts the name of every environment variable read into the
+// configuration, see EnvName.
+const EnvPrefix = "{{$env}}"
+
+// EnvName is the environment variable that overrides key, e.g.
+// {{$env}}_DATABASE_HOST for database.host.
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Load reads the configuration from, in increasing precedence, the defaults
+// below, config.yaml, config.<APP_ENV>.yaml and environment variables or the
+// files named by their _FILE variants, then validates it. APP_ENV defaults
+// to development; the files are looked for in the working directory and
+// ./config and are optional.
 func Load() (*Config, error) {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./config")
-
-	// Set environment variable prefix
-	viper.SetEnvPrefix("")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
-
-	// Set defaults
-	setDefaults()
-
-	// Read config file (optional)
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, err
-		}
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = Development
 	}
 
-	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("./config")
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	// Platforms such as Heroku and Cloud Run assign the port in PORT
+	if err := v.BindEnv("server.port", EnvName("server.port"), "PORT"); err != nil {
 		return nil, err
 	}
 
+	setDefaults(v)
+
+	for _, name := range []string{"config", "config." + env} {
+		v.SetConfigName(name)
+		if err := v.MergeInConfig(); err != nil {
+			var notFound viper.ConfigFileNotFoundError
+			if !errors.As(err, &notFound) {
+				return nil, fmt.Errorf("failed to read %s: %w", v.ConfigFileUsed(), err)
+			}
+		}
+	}
+	if err := readSecretFiles(v); err != nil {
+		return nil, fmt.Errorf("invalid %s configuration:\n%w", env, err)
+	}
+
+	config := Config{Env: env}
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("invalid %s configuration: %w", env, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s configuration:\n%w", env, err)
+	}
 	return &config, nil
 }
 
-func setDefaults() {
-	viper.SetDefault("server.port", "8080")
-	viper.SetDefault("server.mode", "debug")
-	viper.SetDefault("database.host", "localhost")
-	viper.SetDefault("database.port", "5432")
-	viper.SetDefault("database.driver", "postgres")
-	viper.SetDefault("jwt.expires_in", "24h")
-	viper.SetDefault("api.version", "v1")
+// Validate reports every missing or malformed setting at once, each with
+// the environment variable that sets it.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("  %s (%s) %s", key, EnvName(key), fmt.Sprintf(format, args...)))
+		}
+	}
+	production := c.Env == Production
+
+	check(isPort(c.Server.Port), "server.port", "must be a port number, got %q", c.Server.Port)
+	check(oneOf(c.Server.Mode, "debug", "release", "test"), "server.mode", "must be debug, release or test, got %q", c.Server.Mode)
+
+	check(c.Database.Driver == "{{.DBDriver}}", "database.driver", "must be {{.DBDriver}}, got %q", c.Database.Driver)
+	check(c.Database.Host != "", "database.host", "is required")
+	check(isPort(c.Database.Port), "database.port", "must be a port number, got %q", c.Database.Port)
+	check(c.Database.User != "", "database.user", "is required")
+	check(c.Database.Name != "", "database.name", "is required")
+	check(!production || c.Database.Password != "", "database.password", "is required in production")
+
+	check(!production || c.JWT.Secret != "", "jwt.secret", "is required in production")
+
+	// Release mode refuses the example secrets, e.g. from .env.example
+	if c.Server.Mode == "release" {
+		check(!c.Database.Password.IsPlaceholder(), "database.password", "is an example value, set a real password")
+		check(!c.JWT.Secret.IsPlaceholder(), "jwt.secret", "is an example value, set a random secret")
+		check(c.JWT.Secret == "" || len(c.JWT.Secret) >= 32, "jwt.secret", "must be at least 32 characters")
+	}
+	check(isDuration(c.JWT.ExpiresIn), "jwt.expires_in", "must be a duration such as 24h, got %q", c.JWT.ExpiresIn)
+	check(c.API.Version != "", "api.version", "is required")
+	check(c.CORS.MaxAge >= 0, "cors.max_age", "must not be negative")
+{{- if .WithRateLimit}}
+
+	if c.RateLimit.Enabled {
+		check(oneOf(c.RateLimit.Store, "memory", "redis"), "ratelimit.store", "must be memory or redis, got %q", c.RateLimit.Store)
+		check(c.RateLimit.Requests > 0, "ratelimit.requests", "must be positive")
+		check(c.RateLimit.Period > 0, "ratelimit.period", "must be positive")
+		check(c.RateLimit.Burst >= 0, "ratelimit.burst", "must not be negative")
+		check(oneOf(c.RateLimit.KeyBy, "ip", "api_key", "user"), "ratelimit.key_by", "must be ip, api_key or user, got %q", c.RateLimit.KeyBy)
+		check(c.RateLimit.Store != "redis" || c.Redis.Addr != "", "redis.addr", "is required with the redis store")
+	}
+{{- end}}
+{{- if .WithMetrics}}
+
+	if c.Metrics.Enabled {
+		check(isPort(c.Metrics.AdminPort), "metrics.admin_port", "must be a port number, got %q", c.Metrics.AdminPort)
+		check(c.Metrics.AdminPort != c.Server.Port, "metrics.admin_port", "must differ from server.port")
+	}
+{{- end}}
+{{- if .WithTracing}}
+
+	if c.Tracing.Enabled {
+		check(c.Tracing.Endpoint != "", "tracing.endpoint", "is required")
+		check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+{{- end}}
+{{- if .WithJobs}}
+
+	check(c.Jobs.Concurrency > 0, "jobs.concurrency", "must be positive")
+	check(c.Jobs.PollInterval > 0, "jobs.poll_interval", "must be positive")
+	check(c.Jobs.Timeout > 0, "jobs.timeout", "must be positive")
+	check(c.Jobs.MaxAttempts > 0, "jobs.max_attempts", "must be positive")
+	check(c.Jobs.Backoff > 0, "jobs.backoff", "must be positive")
+	check(c.Jobs.MaxBackoff >= c.Jobs.Backoff, "jobs.max_backoff", "must not be less than jobs.backoff")
+{{- end}}
+
+	return errors.Join(errs...)
+}
+
+func isPort(s string) bool {
+	port, err := strconv.Atoi(s)
+	return err == nil && port > 0 && port < 65536
+}
+
+func isDuration(s string) bool {
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+func oneOf(s string, values ...string) bool {
+	return slices.Contains(values, s)
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", "8080")
+	v.SetDefault("server.mode", "debug")
+	v.SetDefault("database.driver", "{{.DBDriver}}")
+	v.SetDefault("database.host", "localhost")
+	v.SetDefault("database.port", "{{if eq .DBDriver "mysql"}}3306{{else}}5432{{end}}")
+	v.SetDefault("database.user", "{{if eq .DBDriver "mysql"}}root{{else}}postgres{{end}}")
+	v.SetDefault("database.password", "")
+	v.SetDefault("database.name", "{{.ProjectName}}_db")
+	v.SetDefault("jwt.secret", "")
+	v.SetDefault("jwt.expires_in", "24h")
+	v.SetDefault("api.version", "v1")
+	v.SetDefault("cors.allowed_origins", []string{})
+	v.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"})
+	v.SetDefault("cors.allowed_headers", []string{"Authorization", "Content-Type", "Accept", "X-Requested-With"})
+	v.SetDefault("cors.allow_credentials", false)
+	v.SetDefault("cors.max_age", 12*time.Hour)
+{{- if .WithRateLimit}}
+	v.SetDefault("ratelimit.enabled", true)
+	v.SetDefault("ratelimit.store", "memory")
+	v.SetDefault("ratelimit.requests", 100)
+	v.SetDefault("ratelimit.period", time.Minute)
+	v.SetDefault("ratelimit.burst", 0)
+	v.SetDefault("ratelimit.key_by", "ip")
+	v.SetDefault("ratelimit.api_key_header", "X-API-Key")
+	v.SetDefault("redis.addr", "localhost:6379")
+	v.SetDefault("redis.password", "")
+	v.SetDefault("redis.db", 0)
+{{- end}}
+{{- if .WithMetrics}}
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.admin_port", "9090")
+	v.SetDefault("metrics.pprof", false)
+	v.SetDefault("metrics.pprof_token", "")
+{{- end}}
+{{- if .WithTracing}}
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.service_name", "{{.ProjectName}}")
+	v.SetDefault("tracing.endpoint", "localhost:4318")
+	v.SetDefault("tracing.insecure", false)
+	v.SetDefault("tracing.sample_ratio", 1.0)
+{{- end}}
+{{- if .WithJobs}}
+	v.SetDefault("jobs.concurrency", 4)
+	v.SetDefault("jobs.poll_interval", time.Second)
+	v.SetDefault("jobs.timeout", 5*time.Minute)
+	v.SetDefault("jobs.max_attempts", 5)
+	v.SetDefault("jobs.backoff", 10*time.Second)
+	v.SetDefault("jobs.max_backoff", time.Hour)
+{{- end}}
+}`,
+
+	"internal/config/secret.go": `{{- $env := envPrefix .ProjectName -}}
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Secret is a setting that must not show up in logs or config dumps. It
+// prints as [REDACTED] with fmt, encoding/json and log/slog, or as "" when
+// unset so missing secrets still stand out; Value returns the secret itself.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+// Value returns the secret in clear text.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// placeholderSecrets are example values, such as the ones .env.example
+// ships with, that release mode refuses.
+var placeholderSecrets = []string{
+{{- range .PlaceholderSecrets}}
+	{{printf "%q" .}},
+{{- end}}
+}
+
+// IsPlaceholder reports whether s is a well-known example value.
+func (s Secret) IsPlaceholder() bool {
+	for _, placeholder := range placeholderSecrets {
+		if strings.EqualFold(string(s), placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// readSecretFiles sets every key whose environment variable has a _FILE
+// variant to the contents of the file it names, the convention for Docker
+// and Kubernetes secrets mounted as files, e.g.
+// {{$env}}_DATABASE_PASSWORD_FILE=/run/secrets/db_password. Setting both the
+// variable and its _FILE variant is an error.
+func readSecretFiles(v *viper.Viper) error {
+	var errs []error
+	for _, key := range v.AllKeys() {
+		name := EnvName(key)
+		path := os.Getenv(name + "_FILE")
+		if path == "" {
+			continue
+		}
+		if _, ok := os.LookupEnv(name); ok {
+			errs = append(errs, fmt.Errorf("  %s: both %s and %s_FILE are set", key, name, name))
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("  %s (%s_FILE): %w", key, name, err))
+			continue
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return errors.Join(errs...)
 }`,
 
 	"internal/database/database.go": `package database
@@ -87,6 +437,7 @@ import (
 	"log"
 
 	"{{.ProjectName}}/internal/config"
+	"{{.ProjectName}}/internal/jobs"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -97,24 +448,28 @@ func NewConnection(cfg *config.Config) (*gorm.DB, error) {
 	var dialector gorm.Dialector
 
 	switch cfg.Database.Driver {
+{{- if eq .DBDriver "postgres"}}
 	case "postgres":
 		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 			cfg.Database.Host,
 			cfg.Database.User,
-			cfg.Database.Password,
+			cfg.Database.Password.Value(),
 			cfg.Database.Name,
 			cfg.Database.Port,
 		)
 		dialector = postgres.Open(dsn)
+{{- end}}
+{{- if eq .DBDriver "mysql"}}
 	case "mysql":
 		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 			cfg.Database.User,
-			cfg.Database.Password,
+			cfg.Database.Password.Value(),
 			cfg.Database.Host,
 			cfg.Database.Port,
 			cfg.Database.Name,
 		)
 		dialector = mysql.Open(dsn)
+{{- end}}
 	default:
 		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Database.Driver)
 	}
@@ -132,6 +487,13 @@ func NewConnection(cfg *config.Config) (*gorm.DB, error) {
 func Migrate(db *gorm.DB) error {
 	// Add your models here for auto-migration
 	// Example: db.AutoMigrate(&models.User{}, &models.Product{})
+{{- if .WithJobs}}
+
+	// The background job queue, see internal/jobs
+	if err := db.AutoMigrate(&jobs.QueuedJob{}); err != nil {
+		return fmt.Errorf("failed to migrate the job queue: %w", err)
+	}
+{{- end}}
 	log.Println("Database migration completed")
 	return nil
 }`,
@@ -139,22 +501,35 @@ func Migrate(db *gorm.DB) error {
 	"internal/server/server.go": `package server
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"time"
 
 	"{{.ProjectName}}/internal/config"
 	"{{.ProjectName}}/internal/database"
 	"{{.ProjectName}}/internal/handlers"
 	"{{.ProjectName}}/internal/middleware"
+	"{{.ProjectName}}/internal/observability"
 	"{{.ProjectName}}/internal/services"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
+// shutdownTimeout is how long in-flight requests get to finish once the
+// server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
 type Server struct {
 	router *gin.Engine
 	db     *gorm.DB
 	config *config.Config
+{{- if .WithMetrics}}
+	metrics *observability.Metrics
+{{- end}}
 }
 
 func New(cfg *config.Config) *Server {
@@ -168,6 +543,16 @@ func New(cfg *config.Config) *Server {
 		db = nil
 	}
 
+{{- if .WithTracing}}
+
+	// Trace queries as children of the span in the context they run with
+	if db != nil && cfg.Tracing.Enabled {
+		if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+			log.Printf("Warning: Failed to trace database queries: %v", err)
+		}
+	}
+{{- end}}
+
 	// Run migrations if database is connected
 	if db != nil {
 		if err := database.Migrate(db); err != nil {
@@ -185,25 +570,99 @@ func New(cfg *config.Config) *Server {
 	router := gin.New()
 
 	// Add middleware
+{{- if .WithTracing}}
+	if cfg.Tracing.Enabled {
+		router.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	}
+{{- end}}
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
-	router.Use(middleware.CORS())
+{{- if .WithMetrics}}
+
+	var metrics *observability.Metrics
+	if cfg.Metrics.Enabled {
+		metrics = observability.NewMetrics()
+		router.Use(metrics.Middleware())
+		if db != nil {
+			if err := metrics.InstrumentDB(db, cfg.Database.Name); err != nil {
+				log.Printf("Warning: Failed to instrument database: %v", err)
+			}
+		}
+	}
+{{end}}
+	router.Use(middleware.CORS(cfg.CORS))
+{{- if .WithRateLimit}}
+
+	var limiter *middleware.RateLimiter
+	if cfg.RateLimit.Enabled {
+		limiter, err = middleware.NewRateLimiterFromConfig(cfg.RateLimit, cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to set up rate limiting: %v", err)
+		}
+	}
+{{- end}}
 
 	// Setup routes
-	setupRoutes(router, handlers, cfg)
+	setupRoutes(router, handlers, cfg{{if .WithRateLimit}}, limiter{{end}})
 
 	return &Server{
 		router: router,
 		db:     db,
 		config: cfg,
+{{- if .WithMetrics}}
+		metrics: metrics,
+{{- end}}
 	}
 }
 
-func (s *Server) Start(addr string) error {
-	return s.router.Run(addr)
+// Start serves on addr until ctx is cancelled, then gives in-flight
+// requests shutdownTimeout to finish before returning.
+func (s *Server) Start(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+{{- if .WithMetrics}}
+
+	var admin *http.Server
+	if s.metrics != nil {
+		admin = observability.NewAdminServer(s.config.Metrics, s.metrics)
+		go func() {
+			log.Printf("Serving metrics on admin port %s", s.config.Metrics.AdminPort)
+			if err := admin.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Warning: Admin server stopped: %v", err)
+			}
+		}()
+	}
+{{- end}}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+{{- if .WithMetrics}}
+	if admin != nil {
+		_ = admin.Shutdown(shutdownCtx)
+	}
+{{- end}}
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
-func setupRoutes(r *gin.Engine, h *handlers.Handlers, cfg *config.Config) {
+func setupRoutes(r *gin.Engine, h *handlers.Handlers, cfg *config.Config{{if .WithRateLimit}}, limiter *middleware.RateLimiter{{end}}) {
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -213,215 +672,1982 @@ func setupRoutes(r *gin.Engine, h *handlers.Handlers, cfg *config.Config) {
 		})
 	})
 
+	// API documentation, generated with 'lupettogo openapi'
+	r.StaticFile("/openapi.yaml", "openapi.yaml")
+	if gin.Mode() == gin.DebugMode {
+		r.GET("/swagger", swaggerUI)
+	}
+
 	// API routes
 	api := r.Group("/api/" + cfg.API.Version)
+{{- if .WithRateLimit}}
+	// Only the API is rate limited, so health checks and the docs are never
+	// rejected. Register authentication middleware before the limiter:
+	// ratelimit.key_by user reads the user ID it stores under
+	// middleware.UserIDKey
+	api.Use(limiter.Handler())
+{{- end}}
 	{
 		// Add your API routes here
 		api.GET("/example", h.Example.GetExample)
 	}
+{{- if .WithRateLimit}}
+
+	// Per-route rate limits replace the default for that route, e.g. for
+	// endpoints that are expensive or attractive to brute force
+	limiter.Route(http.MethodGet, "/api/"+cfg.API.Version+"/example", middleware.RateLimit{Requests: 30, Period: time.Minute, Burst: 10})
+{{- end}}
 }`,
 
-	"internal/middleware/cors.go": `package middleware
+	"internal/server/docs.go": `package server
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
-func CORS() gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, HEAD, PATCH, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
+const swaggerUIPage = ` + "`" + `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>{{.ProjectName}} API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/openapi.yaml", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>` + "`" + `
+
+// swaggerUI serves a Swagger UI page for the spec at /openapi.yaml
+func swaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
 }`,
 
-	"internal/models/example.go": `package models
+	"internal/middleware/cors.go": `package middleware
 
 import (
-	"time"
+	"net/http"
+	"strconv"
+	"strings"
 
-	"gorm.io/gorm"
+	"{{.ProjectName}}/internal/config"
+	"github.com/gin-gonic/gin"
 )
 
-type Example struct {
-	ID        uint           ` + "`" + `json:"id" gorm:"primarykey"` + "`" + `
-	Name      string         ` + "`" + `json:"name" gorm:"not null"` + "`" + `
-	Email     string         ` + "`" + `json:"email" gorm:"uniqueIndex;not null"` + "`" + `
-	Status    string         ` + "`" + `json:"status" gorm:"default:active"` + "`" + `
-	CreatedAt time.Time      ` + "`" + `json:"created_at"` + "`" + `
-	UpdatedAt time.Time      ` + "`" + `json:"updated_at"` + "`" + `
-	DeletedAt gorm.DeletedAt ` + "`" + `json:"-" gorm:"index"` + "`" + `
-}
+// CORS applies cfg to cross-origin requests. Allowed origins are echoed back
+// with Vary: Origin so caches keep responses per origin; preflight requests
+// from other origins are rejected and their other requests get no CORS
+// headers, so browsers block them.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
 
-func (Example) TableName() string {
-	return "examples"
-}`,
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
 
-	"internal/repositories/repositories.go": `package repositories
+		allowed, wildcard := matchOrigin(cfg.AllowedOrigins, origin)
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
 
-import (
-	"gorm.io/gorm"
-)
+		if wildcard {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
 
-type Repositories struct {
-	Example *ExampleRepository
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
 }
 
-func New(db *gorm.DB) *Repositories {
-	return &Repositories{
-		Example: NewExampleRepository(db),
+// matchOrigin reports whether origin is allowed, and whether only by the
+// "*" wildcard.
+func matchOrigin(allowed []string, origin string) (ok, wildcard bool) {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "*":
+			wildcard = true
+		case pattern == origin:
+			return true, false
+		case matchSubdomain(pattern, origin):
+			return true, false
+		}
+	}
+	return wildcard, wildcard
+}
+
+// matchSubdomain matches patterns like https://*.example.com, where * stands
+// for one or more subdomain labels.
+func matchSubdomain(pattern, origin string) bool {
+	prefix, suffix, ok := strings.Cut(pattern, "*")
+	if !ok || !strings.HasPrefix(suffix, ".") {
+		return false
+	}
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
 	}
+	sub := origin[len(prefix) : len(origin)-len(suffix)]
+	return sub != "" && !strings.ContainsAny(sub, "/:@*") && !strings.HasPrefix(sub, ".") && !strings.HasSuffix(sub, ".")
 }`,
 
-	"internal/repositories/example_repository.go": `package repositories
+	"internal/middleware/ratelimit.go": `package middleware
 
 import (
-	"{{.ProjectName}}/internal/models"
-	"gorm.io/gorm"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-type ExampleRepository struct {
-	db *gorm.DB
+// RateLimit allows Requests per Period on average, in bursts of up to Burst
+// requests (Requests when zero).
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
 }
 
-func NewExampleRepository(db *gorm.DB) *ExampleRepository {
-	return &ExampleRepository{
-		db: db,
+func (l RateLimit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
 	}
+	return l.Requests
 }
 
-func (r *ExampleRepository) FindAll() ([]*models.Example, error) {
-	var examples []*models.Example
-	err := r.db.Find(&examples).Error
-	return examples, err
+// RateLimitResult is the state of a client's bucket after a request.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the next request is allowed, when this
+	// one wasn't.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
 }
 
-func (r *ExampleRepository) FindByID(id uint) (*models.Example, error) {
-	var example models.Example
-	err := r.db.First(&example, id).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &example, nil
+// RateLimitStore keeps a token bucket per key.
+type RateLimitStore interface {
+	// Take refills key's bucket up to now and removes a token if it has one.
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error)
 }
 
-func (r *ExampleRepository) Create(example *models.Example) (*models.Example, error) {
-	err := r.db.Create(example).Error
-	return example, err
-}
+// KeyFunc identifies the client a request counts against.
+type KeyFunc func(c *gin.Context) string
 
-func (r *ExampleRepository) Update(example *models.Example) (*models.Example, error) {
-	err := r.db.Save(example).Error
-	return example, err
+// UserIDKey is the context key authentication middleware stores the user
+// ID under, for KeyByUser. The generated project has no authentication
+// middleware; add one that sets it before the limiter runs.
+const UserIDKey = "user_id"
+
+// KeyByIP counts requests per client IP.
+func KeyByIP() KeyFunc {
+	return func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	}
 }
 
-func (r *ExampleRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Example{}, id).Error
-}`,
+// KeyByAPIKey counts requests per API key sent in header, and per IP for
+// requests without one.
+func KeyByAPIKey(header string) KeyFunc {
+	return func(c *gin.Context) string {
+		if key := c.GetHeader(header); key != "" {
+			return "key:" + key
+		}
+		return "ip:" + c.ClientIP()
+	}
+}
 
-	"internal/services/services.go": `package services
+// KeyByUser counts requests per authenticated user, and per IP for
+// anonymous requests. Requests only count per user once authentication
+// middleware running before the limiter has set UserIDKey.
+func KeyByUser() KeyFunc {
+	return func(c *gin.Context) string {
+		if user := c.GetString(UserIDKey); user != "" {
+			return "user:" + user
+		}
+		return "ip:" + c.ClientIP()
+	}
+}
 
-import (
-	"{{.ProjectName}}/internal/repositories"
-	"gorm.io/gorm"
-)
+// RateLimiter limits requests per client with a default limit and
+// per-route overrides.
+type RateLimiter struct {
+	store  RateLimitStore
+	key    KeyFunc
+	limit  RateLimit
+	routes map[string]RateLimit
+	now    func() time.Time
+}
 
-type Services struct {
-	Example *ExampleService
+// NewRateLimiter returns a limiter applying limit to every route.
+func NewRateLimiter(store RateLimitStore, key KeyFunc, limit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		store:  store,
+		key:    key,
+		limit:  limit,
+		routes: map[string]RateLimit{},
+		now:    time.Now,
+	}
 }
 
-func New(db *gorm.DB) *Services {
-	repos := repositories.New(db)
-	
-	return &Services{
-		Example: NewExampleService(repos.Example),
+// NewRateLimiterFromConfig builds a limiter from the ratelimit and redis
+// settings.
+func NewRateLimiterFromConfig(cfg config.RateLimitConfig, redisCfg config.RedisConfig) (*RateLimiter, error) {
+	if cfg.Requests <= 0 || cfg.Period <= 0 {
+		return nil, fmt.Errorf("ratelimit requests and period must be positive")
 	}
-}`,
 
-	"internal/services/example_service.go": `package services
+	var store RateLimitStore
+	switch cfg.Store {
+	case "memory", "":
+		store = NewMemoryStore()
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     redisCfg.Addr,
+			Password: redisCfg.Password.Value(),
+			DB:       redisCfg.DB,
+		})
+		store = NewRedisStore(client, "ratelimit:")
+	default:
+		return nil, fmt.Errorf("unknown ratelimit store %q (expected memory or redis)", cfg.Store)
+	}
 
-import (
-	"{{.ProjectName}}/internal/models"
-	"{{.ProjectName}}/internal/repositories"
-)
+	var key KeyFunc
+	switch cfg.KeyBy {
+	case "ip", "":
+		key = KeyByIP()
+	case "api_key":
+		key = KeyByAPIKey(cfg.APIKeyHeader)
+	case "user":
+		key = KeyByUser()
+	default:
+		return nil, fmt.Errorf("unknown ratelimit key_by %q (expected ip, api_key or user)", cfg.KeyBy)
+	}
 
-type ExampleService struct {
-	exampleRepo *repositories.ExampleRepository
+	return NewRateLimiter(store, key, RateLimit{Requests: cfg.Requests, Period: cfg.Period, Burst: cfg.Burst}), nil
 }
 
-func NewExampleService(exampleRepo *repositories.ExampleRepository) *ExampleService {
-	return &ExampleService{
-		exampleRepo: exampleRepo,
+// Route replaces the default limit for one route, given as its method and
+// path pattern such as "/api/v1/users/:id". Clients get a separate bucket
+// for it. Route does nothing on a nil limiter, so routes can be set up the
+// same way when rate limiting is disabled.
+func (l *RateLimiter) Route(method, path string, limit RateLimit) {
+	if l == nil {
+		return
 	}
+	l.routes[method+" "+path] = limit
 }
 
-func (s *ExampleService) GetExample() map[string]interface{} {
-	return map[string]interface{}{
-		"message": "Hello from LupettoGo! 🐺",
-		"status":  "success",
-		"data": map[string]interface{}{
-			"example": "This is an example response from the service layer",
-			"tips":    "Replace this service with your business logic",
-		},
+// Handler returns the middleware. It sets the RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers on
+// every response, and rejects clients over their limit with 429 and
+// Retry-After. A nil limiter lets every request through.
+func (l *RateLimiter) Handler() gin.HandlerFunc {
+	if l == nil {
+		return func(c *gin.Context) { c.Next() }
 	}
-}
+	return func(c *gin.Context) {
+		limit, scope := l.limit, "default"
+		route := c.Request.Method + " " + c.FullPath()
+		if override, ok := l.routes[route]; ok {
+			limit, scope = override, route
+		}
 
-func (s *ExampleService) GetAllExamples() ([]*models.Example, error) {
-	return s.exampleRepo.FindAll()
+		result, err := l.store.Take(c.Request.Context(), scope+":"+l.key(c), limit, l.now())
+		if err != nil {
+			// Fail open: an unreachable store shouldn't take the API down
+			log.Printf("Warning: rate limit store failed: %v", err)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(limit.burst()))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", seconds(result.Reset))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s;burst=%d", limit.Requests, seconds(limit.Period), limit.burst()))
+
+		if !result.Allowed {
+			header.Set("Retry-After", seconds(result.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "rate limit exceeded",
+			})
+			return
+		}
+
+		c.Next()
+	}
 }
 
-func (s *ExampleService) GetExampleByID(id uint) (*models.Example, error) {
-	return s.exampleRepo.FindByID(id)
+// seconds formats d as whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
 }`,
 
-	"internal/handlers/handlers.go": `package handlers
+	"internal/middleware/ratelimit_store.go": `package middleware
 
 import (
-	"{{.ProjectName}}/internal/services"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
-type Handlers struct {
-	Example *ExampleHandler
+// refill returns the tokens in a bucket elapsed after it held tokens.
+func refill(tokens float64, elapsed time.Duration, limit RateLimit) float64 {
+	if elapsed <= 0 {
+		return tokens
+	}
+	rate := float64(limit.Requests) / float64(limit.Period)
+	return math.Min(float64(limit.burst()), tokens+float64(elapsed)*rate)
 }
 
-func New(services *services.Services) *Handlers {
-	return &Handlers{
-		Example: NewExampleHandler(services.Example),
+// bucketResult describes a bucket left with tokens after a request.
+func bucketResult(allowed bool, tokens float64, limit RateLimit) RateLimitResult {
+	perToken := float64(limit.Period) / float64(limit.Requests)
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Remaining: int(tokens),
+		Reset:     time.Duration((float64(limit.burst()) - tokens) * perToken),
 	}
-}`,
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+	return result
+}
 
-	"internal/handlers/example_handler.go": `package handlers
+// MemoryStore keeps buckets in process memory. Each instance of the server
+// counts separately, so use RedisStore when running more than one.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// sweepInterval is how often full buckets, which are the same as missing
+// ones, are dropped.
+const sweepInterval = time.Minute
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.burst()), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = refill(b.tokens, now.Sub(b.updated), limit)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	result := bucketResult(allowed, b.tokens, limit)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// RedisStore keeps buckets in Redis so every instance of the server shares
+// them. Keys expire once their bucket would be full again.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore returns a store keeping buckets under prefix.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// takeScript refills and takes from a bucket atomically. Times are in
+// milliseconds; tokens are returned as a string because Redis truncates
+// Lua numbers to integers.
+var takeScript = redis.NewScript(` + "`" + `
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+if now > updated then
+  tokens = math.min(burst, tokens + (now - updated) * rate)
+  updated = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", updated)
+redis.call("PEXPIRE", KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+` + "`" + `)
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	perMilli := float64(limit.Requests) / float64(limit.Period.Milliseconds())
+	ttl := time.Duration(float64(limit.burst())/float64(limit.Requests)*float64(limit.Period)) + time.Second
+
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.burst(), perMilli, now.UnixMilli(), ttl.Milliseconds()).Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	if len(reply) != 2 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script reply %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokensReply, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensReply, 64)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script reply %v", reply)
+	}
+	return bucketResult(allowed == 1, tokens, limit), nil
+}`,
+
+	"internal/observability/metrics.go": `// Package observability exposes Prometheus metrics for HTTP requests,
+// database queries and the Go runtime, and serves them with pprof on a
+// separate admin port.
+package observability
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+// Metrics holds the collectors of one server, registered on their own
+// registry so tests and multiple servers don't clash.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	requestSize     *prometheus.HistogramVec
+	responseSize    *prometheus.HistogramVec
+
+	queries       *prometheus.CounterVec
+	queryDuration *prometheus.HistogramVec
+}
+
+// sizeBuckets cover bodies from 100 bytes to 10 MB.
+var sizeBuckets = prometheus.ExponentialBuckets(100, 10, 6)
+
+// NewMetrics creates the collectors, including the Go runtime and process
+// ones.
+func NewMetrics() *Metrics {
+	labels := []string{"method", "route", "status"}
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route and status.",
+		}, labels),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_size_bytes",
+			Help:    "HTTP request body size by method, route and status.",
+			Buckets: sizeBuckets,
+		}, labels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "HTTP response body size by method, route and status.",
+			Buckets: sizeBuckets,
+		}, labels),
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_queries_total",
+			Help: "Database queries by operation, table and result.",
+		}, []string{"operation", "table", "result"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Database query latency by operation and table.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.requestDuration, m.requestSize, m.responseSize,
+		m.queries, m.queryDuration,
+	)
+	return m
+}
+
+// Middleware records every request under its route pattern, so
+// /users/:id is one series however many users there are. Requests that
+// match no route are recorded as "unmatched".
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"method": c.Request.Method,
+			"route":  route,
+			"status": strconv.Itoa(c.Writer.Status()),
+		}
+
+		m.requests.With(labels).Inc()
+		m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+		if c.Request.ContentLength > 0 {
+			m.requestSize.With(labels).Observe(float64(c.Request.ContentLength))
+		}
+		if size := c.Writer.Size(); size > 0 {
+			m.responseSize.With(labels).Observe(float64(size))
+		}
+	}
+}
+
+const queryStartKey = "observability:query_start"
+
+// InstrumentDB records the latency and outcome of every query run through
+// db, and the connection pool statistics of the underlying sql.DB.
+func (m *Metrics) InstrumentDB(db *gorm.DB, name string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := m.Registry.Register(collectors.NewDBStatsCollector(sqlDB, name)); err != nil {
+		return err
+	}
+
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(queryStartKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			value, ok := tx.InstanceGet(queryStartKey)
+			if !ok {
+				return
+			}
+			table := tx.Statement.Table
+			if table == "" {
+				table = "unknown"
+			}
+			result := "ok"
+			if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+				result = "error"
+			}
+			m.queries.WithLabelValues(operation, table, result).Inc()
+			m.queryDuration.WithLabelValues(operation, table).Observe(time.Since(value.(time.Time)).Seconds())
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("observability:before_create", before),
+		callbacks.Create().After("gorm:create").Register("observability:after_create", after("create")),
+		callbacks.Query().Before("gorm:query").Register("observability:before_query", before),
+		callbacks.Query().After("gorm:query").Register("observability:after_query", after("query")),
+		callbacks.Update().Before("gorm:update").Register("observability:before_update", before),
+		callbacks.Update().After("gorm:update").Register("observability:after_update", after("update")),
+		callbacks.Delete().Before("gorm:delete").Register("observability:before_delete", before),
+		callbacks.Delete().After("gorm:delete").Register("observability:after_delete", after("delete")),
+		callbacks.Row().Before("gorm:row").Register("observability:before_row", before),
+		callbacks.Row().After("gorm:row").Register("observability:after_row", after("row")),
+		callbacks.Raw().Before("gorm:raw").Register("observability:before_raw", before),
+		callbacks.Raw().After("gorm:raw").Register("observability:after_raw", after("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}`,
+
+	"internal/observability/admin.go": `package observability
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewAdminServer returns the server for the admin port: /metrics, and
+// /debug/pprof when cfg.Pprof is set. It is kept off the public port so
+// neither is exposed with the API.
+func NewAdminServer(cfg config.MetricsConfig, metrics *Metrics) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.AdminPort,
+		Handler:           AdminHandler(cfg, metrics),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// AdminHandler routes the admin endpoints.
+func AdminHandler(cfg config.MetricsConfig, metrics *Metrics) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+
+	if cfg.Pprof {
+		guard := pprofGuard(cfg.PprofToken.Value())
+		mux.Handle("/debug/pprof/", guard(http.HandlerFunc(pprof.Index)))
+		mux.Handle("/debug/pprof/cmdline", guard(http.HandlerFunc(pprof.Cmdline)))
+		mux.Handle("/debug/pprof/profile", guard(http.HandlerFunc(pprof.Profile)))
+		mux.Handle("/debug/pprof/symbol", guard(http.HandlerFunc(pprof.Symbol)))
+		mux.Handle("/debug/pprof/trace", guard(http.HandlerFunc(pprof.Trace)))
+	}
+	return mux
+}
+
+// pprofGuard only lets through requests with the bearer token, or without
+// a token configured, requests from the local machine. Profiles reveal
+// memory contents and are expensive to take.
+func pprofGuard(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token != "" {
+				given := r.Header.Get("Authorization")
+				if subtle.ConstantTimeCompare([]byte(given), []byte("Bearer "+token)) != 1 {
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+			} else if !isLoopback(r.RemoteAddr) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}`,
+
+	"internal/telemetry/tracing.go": `// Package telemetry sets up OpenTelemetry tracing. Spans start in the HTTP
+// middleware, continue in services through the request context and end in
+// the database plugin, and are exported over OTLP/HTTP.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"{{.ProjectName}}/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Setup installs the global tracer provider and propagator described by cfg
+// and returns a function that flushes and stops the provider. With tracing
+// disabled the provider is a no-op, so instrumented code runs unchanged in
+// tests and without a collector.
+func Setup(ctx context.Context, cfg config.TracingConfig, version string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	// OTEL_RESOURCE_ATTRIBUTES, read last, can add to or override these
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(version),
+		),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe service for tracing: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}`,
+
+	"internal/jobs/job.go": `// Package jobs runs work in the background, away from the request that
+// asked for it. Jobs are rows of the jobs table, run by the worker in
+// cmd/worker; a failed attempt is retried with exponential backoff and a
+// job that runs out of attempts is dead-lettered, kept in the table to
+// inspect and retry.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Job is a kind of background work, identified by its Type. Handle gets
+// the payload the job was enqueued with; an error means the attempt failed
+// and the job is tried again later, unless the error is Permanent.
+type Job interface {
+	Type() string
+	Handle(ctx context.Context, payload json.RawMessage) error
+}
+
+// Registry maps job types to the jobs that handle them.
+type Registry struct {
+	jobs map[string]Job
+}
+
+func NewRegistry(jobs ...Job) (*Registry, error) {
+	r := &Registry{jobs: map[string]Job{}}
+	for _, job := range jobs {
+		if _, ok := r.jobs[job.Type()]; ok {
+			return nil, fmt.Errorf("job type %q is registered twice", job.Type())
+		}
+		r.jobs[job.Type()] = job
+	}
+	return r, nil
+}
+
+// Lookup returns the job registered for jobType.
+func (r *Registry) Lookup(jobType string) (Job, bool) {
+	job, ok := r.jobs[jobType]
+	return job, ok
+}
+
+// Types returns the registered job types, sorted.
+func (r *Registry) Types() []string {
+	types := make([]string, 0, len(r.jobs))
+	for jobType := range r.jobs {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one trying again won't fix, such as an invalid
+// payload. The job is dead-lettered straight away.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or an error it wraps, is Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}`,
+
+	"internal/jobs/queue.go": `package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Statuses of a queued job. Pending jobs wait for RunAt, running jobs are
+// held by a worker and dead jobs ran out of attempts. Jobs that succeed are
+// removed from the queue.
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDead    = "dead"
+)
+
+// abandonGrace is how long past jobs.timeout a running job is left to its
+// worker before the job counts as abandoned, e.g. because the worker
+// crashed, and another worker takes it over.
+const abandonGrace = time.Minute
+
+var (
+	// ErrNoJob is returned by Reserve when no job is ready to run.
+	ErrNoJob = errors.New("no job is ready")
+	// ErrNotHeld is returned when a job is completed or failed after its
+	// worker lost it, because the job was abandoned and taken over.
+	ErrNotHeld = errors.New("job is no longer held by this worker")
+)
+
+// QueuedJob is a job in the queue.
+type QueuedJob struct {
+	ID          uint       ` + "`" + `json:"id" gorm:"primarykey"` + "`" + `
+	Type        string     ` + "`" + `json:"type" gorm:"size:100;not null"` + "`" + `
+	Payload     string     ` + "`" + `json:"payload" gorm:"type:text;not null"` + "`" + `
+	Status      string     ` + "`" + `json:"status" gorm:"size:20;not null;index:idx_jobs_ready,priority:1"` + "`" + `
+	RunAt       time.Time  ` + "`" + `json:"run_at" gorm:"not null;index:idx_jobs_ready,priority:2"` + "`" + `
+	Attempts    int        ` + "`" + `json:"attempts" gorm:"not null"` + "`" + `
+	MaxAttempts int        ` + "`" + `json:"max_attempts" gorm:"not null"` + "`" + `
+	LockedAt    *time.Time ` + "`" + `json:"locked_at"` + "`" + `
+	LastError   string     ` + "`" + `json:"last_error" gorm:"type:text"` + "`" + `
+	CreatedAt   time.Time  ` + "`" + `json:"created_at"` + "`" + `
+	UpdatedAt   time.Time  ` + "`" + `json:"updated_at"` + "`" + `
+}
+
+func (QueuedJob) TableName() string {
+	return "jobs"
+}
+
+// Queue keeps jobs in the database. Any number of workers can take jobs
+// from it at once: a job is reserved with SELECT ... FOR UPDATE SKIP
+// LOCKED, which needs PostgreSQL 9.5 or MySQL 8.0 or later.
+type Queue struct {
+	db  *gorm.DB
+	cfg config.JobsConfig
+}
+
+func NewQueue(db *gorm.DB, cfg config.JobsConfig) *Queue {
+	return &Queue{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// WithTx returns a queue that enqueues in the transaction tx, so jobs are
+// only run if the changes they follow from are committed.
+func (q *Queue) WithTx(tx *gorm.DB) *Queue {
+	return &Queue{db: tx, cfg: q.cfg}
+}
+
+// EnqueueOption changes how a job is enqueued.
+type EnqueueOption func(*QueuedJob)
+
+// WithDelay runs the job no earlier than d from now.
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(job *QueuedJob) {
+		job.RunAt = job.RunAt.Add(d)
+	}
+}
+
+// WithMaxAttempts gives the job n attempts instead of jobs.max_attempts.
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(job *QueuedJob) {
+		job.MaxAttempts = n
+	}
+}
+
+// Enqueue adds a job of jobType to the queue, with payload encoded as JSON.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload any, opts ...EnqueueOption) (*QueuedJob, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", jobType, err)
+	}
+
+	job := &QueuedJob{
+		Type:        jobType,
+		Payload:     string(encoded),
+		Status:      StatusPending,
+		RunAt:       time.Now(),
+		MaxAttempts: q.cfg.MaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if err := q.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue %s job: %w", jobType, err)
+	}
+	return job, nil
+}
+
+// Reserve takes the job that has been ready longest, or an abandoned one,
+// marks it running and counts the attempt. It returns ErrNoJob when no job
+// is ready. Jobs abandoned on their last attempt are dead-lettered instead.
+func (q *Queue) Reserve(ctx context.Context) (*QueuedJob, error) {
+	for {
+		job, err := q.reserve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if job.Status == StatusRunning {
+			return job, nil
+		}
+	}
+}
+
+func (q *Queue) reserve(ctx context.Context) (*QueuedJob, error) {
+	var job *QueuedJob
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var ready []*QueuedJob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", StatusPending, now).
+			Or("status = ? AND locked_at < ?", StatusRunning, now.Add(-q.cfg.Timeout-abandonGrace)).
+			Order("run_at, id").
+			Limit(1).
+			Find(&ready).Error
+		if err != nil {
+			return err
+		}
+		if len(ready) == 0 {
+			return ErrNoJob
+		}
+		job = ready[0]
+
+		updates := map[string]any{}
+		if job.Attempts >= job.MaxAttempts {
+			job.Status = StatusDead
+			job.LockedAt = nil
+			updates["last_error"] = "abandoned on its last attempt"
+		} else {
+			job.Status = StatusRunning
+			job.Attempts++
+			job.LockedAt = &now
+		}
+		updates["status"] = job.Status
+		updates["attempts"] = job.Attempts
+		updates["locked_at"] = job.LockedAt
+		return tx.Model(job).Updates(updates).Error
+	})
+	if errors.Is(err, ErrNoJob) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve job: %w", err)
+	}
+	return job, nil
+}
+
+// Complete removes a job that succeeded from the queue.
+func (q *Queue) Complete(ctx context.Context, job *QueuedJob) error {
+	result := q.held(ctx, job).Delete(&QueuedJob{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to complete job %d: %w", job.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Fail records the error of the attempt. The job is tried again after
+// Backoff, unless it has no attempts left or err is Permanent, in which
+// case it is dead-lettered.
+func (q *Queue) Fail(ctx context.Context, job *QueuedJob, err error) error {
+	updates := map[string]any{"locked_at": nil, "last_error": err.Error()}
+	if job.Attempts >= job.MaxAttempts || IsPermanent(err) {
+		updates["status"] = StatusDead
+	} else {
+		updates["status"] = StatusPending
+		updates["run_at"] = time.Now().Add(Backoff(q.cfg, job.Attempts))
+	}
+
+	result := q.held(ctx, job).Model(&QueuedJob{}).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to record failure of job %d: %w", job.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// held selects job as long as it is running the attempt it was reserved
+// for, and not taken over since.
+func (q *Queue) held(ctx context.Context, job *QueuedJob) *gorm.DB {
+	return q.db.WithContext(ctx).Where("id = ? AND status = ? AND attempts = ?", job.ID, StatusRunning, job.Attempts)
+}
+
+// Dead returns up to limit dead jobs, most recently failed first.
+func (q *Queue) Dead(ctx context.Context, limit int) ([]*QueuedJob, error) {
+	var jobs []*QueuedJob
+	err := q.db.WithContext(ctx).Where("status = ?", StatusDead).Order("updated_at DESC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// Retry puts a dead job back in the queue with all its attempts.
+func (q *Queue) Retry(ctx context.Context, id uint) error {
+	result := q.db.WithContext(ctx).Model(&QueuedJob{}).
+		Where("id = ? AND status = ?", id, StatusDead).
+		Updates(map[string]any{"status": StatusPending, "attempts": 0, "run_at": time.Now(), "last_error": ""})
+	if result.Error != nil {
+		return fmt.Errorf("failed to retry job %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no dead job with id %d", id)
+	}
+	return nil
+}
+
+// Backoff is how long a job waits after its attempt-th failed attempt:
+// jobs.backoff, doubled for every earlier failure up to jobs.max_backoff,
+// plus up to a tenth more so jobs that failed together don't all retry at
+// once.
+func Backoff(cfg config.JobsConfig, attempt int) time.Duration {
+	delay := cfg.MaxBackoff
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt <= 32 {
+		if d := cfg.Backoff << (attempt - 1); d > 0 && d < delay {
+			delay = d
+		}
+	}
+	return delay + rand.N(delay/10+1)
+}`,
+
+	"internal/jobs/worker.go": `package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+{{- if .WithTracing}}
+
+// tracer starts a span for every attempt at a job.
+var tracer = otel.Tracer("{{.ProjectName}}/internal/jobs")
+{{- end}}
+
+// Store is the queue as the worker pool uses it; tests pass a fake.
+type Store interface {
+	Reserve(ctx context.Context) (*QueuedJob, error)
+	Complete(ctx context.Context, job *QueuedJob) error
+	Fail(ctx context.Context, job *QueuedJob, err error) error
+}
+
+// Pool runs queued jobs, up to jobs.concurrency at once.
+type Pool struct {
+	store    Store
+	registry *Registry
+	cfg      config.JobsConfig
+}
+
+func NewPool(store Store, registry *Registry, cfg config.JobsConfig) *Pool {
+	return &Pool{
+		store:    store,
+		registry: registry,
+		cfg:      cfg,
+	}
+}
+
+// Run works through the queue until ctx is cancelled, then waits for the
+// jobs in progress, which get the rest of jobs.timeout to finish.
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range p.cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work runs one job at a time, polling the queue every jobs.poll_interval
+// while it is empty.
+func (p *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := p.store.Reserve(ctx)
+		if err == nil {
+			p.process(ctx, job)
+			continue
+		}
+		if !errors.Is(err, ErrNoJob) && ctx.Err() == nil {
+			log.Printf("Warning: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.cfg.PollInterval):
+		}
+	}
+}
+
+// process makes an attempt at job and records the outcome. Cancelling ctx
+// doesn't stop the attempt, so shutting down doesn't waste it.
+func (p *Pool) process(ctx context.Context, job *QueuedJob) {
+	ctx = context.WithoutCancel(ctx)
+	attemptCtx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+{{- if .WithTracing}}
+
+	attemptCtx, span := tracer.Start(attemptCtx, "job "+job.Type, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		attribute.Int("job.id", int(job.ID)),
+		attribute.Int("job.attempt", job.Attempts),
+	))
+	defer span.End()
+{{- end}}
+
+	err := p.attempt(attemptCtx, job)
+	if err == nil {
+		if err := p.store.Complete(ctx, job); err != nil {
+			log.Printf("Warning: Failed to complete job %d: %v", job.ID, err)
+		}
+		return
+	}
+{{- if .WithTracing}}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+{{- end}}
+
+	log.Printf("Job %d (%s) failed attempt %d of %d: %v", job.ID, job.Type, job.Attempts, job.MaxAttempts, err)
+	if err := p.store.Fail(ctx, job, err); err != nil {
+		log.Printf("Warning: Failed to record failure of job %d: %v", job.ID, err)
+	}
+}
+
+// attempt runs the job registered for the type of job, turning a panic
+// into an error. An unknown type fails like any other attempt: the worker
+// may be older than the code that enqueued the job.
+func (p *Pool) attempt(ctx context.Context, job *QueuedJob) (err error) {
+	handler, ok := p.registry.Lookup(job.Type)
+	if !ok {
+		return fmt.Errorf("no job registered for type %q", job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler.Handle(ctx, json.RawMessage(job.Payload))
+}`,
+
+	"internal/jobs/registry.go": `package jobs
+
+import (
+	"gorm.io/gorm"
+)
+
+// All returns the jobs the worker runs. Add the jobs you generate with
+// 'lupettogo generate job' here.
+func All(db *gorm.DB) []Job {
+	return []Job{
+		NewExample(),
+	}
+}`,
+
+	"internal/jobs/example_job.go": `package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// ExampleType identifies Example jobs in the queue.
+const ExampleType = "example"
+
+// ExamplePayload is the input of an Example job, stored as JSON.
+type ExamplePayload struct {
+	Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+// Example logs the message it is enqueued with. Replace it with your own
+// jobs, or add them with 'lupettogo generate job'.
+type Example struct{}
+
+func NewExample() *Example {
+	return &Example{}
+}
+
+func (j *Example) Type() string {
+	return ExampleType
+}
+
+func (j *Example) Handle(ctx context.Context, payload json.RawMessage) error {
+	var p ExamplePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return Permanent(fmt.Errorf("invalid %s payload: %w", ExampleType, err))
+	}
+	if p.Message == "" {
+		return Permanent(fmt.Errorf("invalid %s payload: message is required", ExampleType))
+	}
+
+	log.Printf("Example job: %s", p.Message)
+	return nil
+}
+
+// EnqueueExample adds an Example job to the queue.
+func (q *Queue) EnqueueExample(ctx context.Context, payload ExamplePayload, opts ...EnqueueOption) (*QueuedJob, error) {
+	return q.Enqueue(ctx, ExampleType, payload, opts...)
+}`,
+
+	"internal/cache/cache.go": `// Package cache keeps values loaded from slower storage, such as the
+// database, in Redis or process memory for a while. Reads go through
+// GetOrLoad, which loads a missing value once however many requests ask for
+// it at the same time; writes Delete the keys they make stale.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// Store keeps encoded values under string keys.
+type Store interface {
+	// Get returns the value stored under key, with ok false when there is
+	// none or it has expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Cache keeps values as JSON in a Store for up to its TTL. Each entry's TTL
+// is shortened by up to a tenth so entries written together don't all
+// expire, and get loaded again, at the same moment.
+type Cache struct {
+	store Store
+	ttl   time.Duration
+	loads singleflight.Group
+}
+
+// New returns a cache using the store selected by cfg.Driver.
+func New(cfg Config) (*Cache, error) {
+	if cfg.TTL <= 0 {
+		return nil, fmt.Errorf("cache ttl must be positive")
+	}
+
+	var store Store
+	switch cfg.Driver {
+	case "memory", "":
+		store = NewMemoryStore(cfg.Size)
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password.Value(),
+			DB:       cfg.Redis.DB,
+		})
+		store = NewRedisStore(client, cfg.Prefix)
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q (expected memory or redis)", cfg.Driver)
+	}
+
+	return NewWithStore(store, cfg.TTL), nil
+}
+
+// NewWithStore returns a cache keeping values in store for up to ttl.
+func NewWithStore(store Store, ttl time.Duration) *Cache {
+	return &Cache{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// GetOrLoad returns the value cached under key, or calls load and caches
+// what it returns, including nil for a record that doesn't exist. Errors
+// from load aren't cached. Concurrent calls for a missing key share a single
+// call to load, so an expiring entry doesn't send every request to the
+// database at once; a key must therefore always hold the same type.
+//
+// The cache only speeds things up: when the store fails, GetOrLoad logs a
+// warning and returns what load returns.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	if value, ok := get[T](ctx, c, key); ok {
+		return value, nil
+	}
+
+	loaded := c.loads.DoChan(key, func() (any, error) {
+		// The load is shared, so one caller giving up mustn't cancel it
+		ctx := context.WithoutCancel(ctx)
+		// Another load may have finished since the first look
+		if value, ok := get[T](ctx, c, key); ok {
+			return value, nil
+		}
+
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.set(ctx, key, value)
+		return value, nil
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-loaded:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		value, _ := result.Val.(T)
+		return value, nil
+	}
+}
+
+// Delete removes keys, e.g. after the records they hold have changed.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := c.store.Delete(ctx, keys...); err != nil {
+		return fmt.Errorf("failed to delete cache keys: %w", err)
+	}
+	return nil
+}
+
+func get[T any](ctx context.Context, c *Cache, key string) (T, bool) {
+	var zero T
+	data, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		log.Printf("Warning: cache get %s failed: %v", key, err)
+		return zero, false
+	}
+	if !ok {
+		return zero, false
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		log.Printf("Warning: cache entry %s is unreadable: %v", key, err)
+		return zero, false
+	}
+	return value, true
+}
+
+func (c *Cache) set(ctx context.Context, key string, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Warning: cache entry %s can't be encoded: %v", key, err)
+		return
+	}
+	if err := c.store.Set(ctx, key, data, c.jitteredTTL()); err != nil {
+		log.Printf("Warning: cache set %s failed: %v", key, err)
+	}
+}
+
+// jitteredTTL returns the TTL less a random share of up to a tenth of it.
+func (c *Cache) jitteredTTL() time.Duration {
+	return c.ttl - time.Duration(rand.Int64N(int64(c.ttl)/10+1))
+}`,
+
+	"internal/cache/config.go": `{{- $env := envPrefix .ProjectName -}}
+package cache
 
 import (
-	"net/http"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"github.com/spf13/viper"
+)
+
+// Config is the cache section of the configuration. Driver is memory or
+// redis; Size bounds the memory store and Prefix starts every Redis key.
+type Config struct {
+	Driver string        ` + "`" + `mapstructure:"driver"` + "`" + `
+	TTL    time.Duration ` + "`" + `mapstructure:"ttl"` + "`" + `
+	Size   int           ` + "`" + `mapstructure:"size"` + "`" + `
+	Prefix string        ` + "`" + `mapstructure:"prefix"` + "`" + `
+	Redis  RedisConfig   ` + "`" + `mapstructure:"redis"` + "`" + `
+}
+
+type RedisConfig struct {
+	Addr     string        ` + "`" + `mapstructure:"addr"` + "`" + `
+	Password config.Secret ` + "`" + `mapstructure:"password"` + "`" + `
+	DB       int           ` + "`" + `mapstructure:"db"` + "`" + `
+}
+
+// LoadConfig reads the cache section the way config.Load reads the rest:
+// from the defaults below, config.yaml, config.<APP_ENV>.yaml and
+// environment variables such as {{$env}}_CACHE_DRIVER, with
+// {{$env}}_CACHE_REDIS_PASSWORD_FILE naming a file holding the password.
+func LoadConfig() (Config, error) {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = config.Development
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("./config")
+
+	v.SetEnvPrefix(config.EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	setDefaults(v)
+
+	for _, name := range []string{"config", "config." + env} {
+		v.SetConfigName(name)
+		if err := v.MergeInConfig(); err != nil {
+			var notFound viper.ConfigFileNotFoundError
+			if !errors.As(err, &notFound) {
+				return Config{}, fmt.Errorf("failed to read %s: %w", v.ConfigFileUsed(), err)
+			}
+		}
+	}
+	if err := readPasswordFile(v); err != nil {
+		return Config{}, fmt.Errorf("invalid %s cache configuration:\n%w", env, err)
+	}
+
+	var settings struct {
+		Cache Config ` + "`" + `mapstructure:"cache"` + "`" + `
+	}
+	if err := v.Unmarshal(&settings); err != nil {
+		return Config{}, fmt.Errorf("invalid %s cache configuration: %w", env, err)
+	}
+	if err := settings.Cache.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid %s cache configuration:\n%w", env, err)
+	}
+	return settings.Cache, nil
+}
+
+// Validate reports every malformed setting at once, each with the
+// environment variable that sets it.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("  %s (%s) %s", key, config.EnvName(key), fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(c.Driver == "memory" || c.Driver == "redis", "cache.driver", "must be memory or redis, got %q", c.Driver)
+	check(c.TTL > 0, "cache.ttl", "must be positive")
+	check(c.Driver != "memory" || c.Size > 0, "cache.size", "must be positive with the memory driver")
+	check(c.Driver != "redis" || c.Redis.Addr != "", "cache.redis.addr", "is required with the redis driver")
+
+	return errors.Join(errs...)
+}
+
+// readPasswordFile sets cache.redis.password from the file named by its
+// _FILE variable, like config.Load does for the other secrets.
+func readPasswordFile(v *viper.Viper) error {
+	const key = "cache.redis.password"
+	name := config.EnvName(key)
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return nil
+	}
+	if _, ok := os.LookupEnv(name); ok {
+		return fmt.Errorf("  %s: both %s and %s_FILE are set", key, name, name)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("  %s (%s_FILE): %w", key, name, err)
+	}
+	v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	return nil
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("cache.driver", "memory")
+	v.SetDefault("cache.ttl", 5*time.Minute)
+	v.SetDefault("cache.size", 10000)
+	v.SetDefault("cache.prefix", "cache:")
+	v.SetDefault("cache.redis.addr", "localhost:6379")
+	v.SetDefault("cache.redis.password", "")
+	v.SetDefault("cache.redis.db", 0)
+}`,
+
+	"internal/cache/memory.go": `package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps up to size entries in process memory, evicting the
+// least recently used one when full. Each instance of the server has its
+// own, so one instance doesn't see another's evictions; use RedisStore when
+// running more than one.
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// order holds the entries, most recently used first
+	order *list.List
+	now   func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty store holding up to size entries, or any
+// number of them when size isn't positive.
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !s.now().Before(entry.expires) {
+		s.remove(element)
+		return nil, false, nil
+	}
+
+	s.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &memoryEntry{
+		key:     key,
+		value:   append([]byte(nil), value...),
+		expires: s.now().Add(ttl),
+	}
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.order.MoveToFront(element)
+		return nil
+	}
+
+	s.entries[key] = s.order.PushFront(entry)
+	for s.size > 0 && s.order.Len() > s.size {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := s.entries[key]; ok {
+			s.remove(element)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet removed.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*memoryEntry).key)
+}`,
+
+	"internal/cache/redis.go": `package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps entries in Redis so every instance of the server shares
+// them, and sees the others' evictions. Redis expires entries itself.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore returns a store keeping entries under prefix.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+	return s.client.Del(ctx, prefixed...).Err()
+}`,
+
+	"internal/models/example.go": `package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Example struct {
+	ID        uint           ` + "`" + `json:"id" gorm:"primarykey"` + "`" + `
+	Name      string         ` + "`" + `json:"name" gorm:"not null"` + "`" + `
+	Email     string         ` + "`" + `json:"email" gorm:"uniqueIndex;not null"` + "`" + `
+	Status    string         ` + "`" + `json:"status" gorm:"default:active"` + "`" + `
+	CreatedAt time.Time      ` + "`" + `json:"created_at"` + "`" + `
+	UpdatedAt time.Time      ` + "`" + `json:"updated_at"` + "`" + `
+	DeletedAt gorm.DeletedAt ` + "`" + `json:"-" gorm:"index"` + "`" + `
+}
+
+func (Example) TableName() string {
+	return "examples"
+}`,
+
+	"internal/repositories/repositories.go": `package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+type Repositories struct {
+	Example *ExampleRepository
+}
+
+func New(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Example: NewExampleRepository(db),
+	}
+}`,
+
+	"internal/repositories/example_repository.go": `package repositories
+
+import (
+	"context"
+
+	"{{.ProjectName}}/internal/models"
+	"gorm.io/gorm"
+)
+
+type ExampleRepository struct {
+	db *gorm.DB
+}
+
+func NewExampleRepository(db *gorm.DB) *ExampleRepository {
+	return &ExampleRepository{
+		db: db,
+	}
+}
+
+func (r *ExampleRepository) FindAll(ctx context.Context) ([]*models.Example, error) {
+	var examples []*models.Example
+	err := r.db.WithContext(ctx).Find(&examples).Error
+	return examples, err
+}
+
+func (r *ExampleRepository) FindByID(ctx context.Context, id uint) (*models.Example, error) {
+	var example models.Example
+	err := r.db.WithContext(ctx).First(&example, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &example, nil
+}
+
+func (r *ExampleRepository) Create(ctx context.Context, example *models.Example) (*models.Example, error) {
+	err := r.db.WithContext(ctx).Create(example).Error
+	return example, err
+}
+
+func (r *ExampleRepository) Update(ctx context.Context, example *models.Example) (*models.Example, error) {
+	err := r.db.WithContext(ctx).Save(example).Error
+	return example, err
+}
+
+func (r *ExampleRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Example{}, id).Error
+}`,
+
+	"internal/services/services.go": `package services
+
+import (
+	"{{.ProjectName}}/internal/repositories"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+)
+{{- if .WithTracing}}
+
+// tracer starts the spans service methods run in, nested under the span of
+// the request that called them.
+var tracer = otel.Tracer("{{.ProjectName}}/internal/services")
+{{- end}}
+
+type Services struct {
+	Example *ExampleService
+}
+
+func New(db *gorm.DB) *Services {
+	repos := repositories.New(db)
+	
+	return &Services{
+		Example: NewExampleService(repos.Example),
+	}
+}`,
+
+	"internal/services/example_service.go": `package services
+
+import (
+	"context"
+
+	"{{.ProjectName}}/internal/models"
+)
+
+// ExampleRepository is the storage ExampleService needs; tests pass a mock.
+type ExampleRepository interface {
+	FindAll(ctx context.Context) ([]*models.Example, error)
+	FindByID(ctx context.Context, id uint) (*models.Example, error)
+}
+
+type ExampleService struct {
+	exampleRepo ExampleRepository
+}
+
+func NewExampleService(exampleRepo ExampleRepository) *ExampleService {
+	return &ExampleService{
+		exampleRepo: exampleRepo,
+	}
+}
+
+func (s *ExampleService) GetExample(ctx context.Context) map[string]interface{} {
+{{- if .WithTracing}}
+	_, span := tracer.Start(ctx, "ExampleService.GetExample")
+	defer span.End()
+{{end}}
+	return map[string]interface{}{
+		"message": "Hello from LupettoGo! 🐺",
+		"status":  "success",
+		"data": map[string]interface{}{
+			"example": "This is an example response from the service layer",
+			"tips":    "Replace this service with your business logic",
+		},
+	}
+}
+
+func (s *ExampleService) GetAllExamples(ctx context.Context) ([]*models.Example, error) {
+{{- if .WithTracing}}
+	ctx, span := tracer.Start(ctx, "ExampleService.GetAllExamples")
+	defer span.End()
+{{end}}
+	return s.exampleRepo.FindAll(ctx)
+}
 
+func (s *ExampleService) GetExampleByID(ctx context.Context, id uint) (*models.Example, error) {
+{{- if .WithTracing}}
+	ctx, span := tracer.Start(ctx, "ExampleService.GetExampleByID")
+	defer span.End()
+{{end}}
+	return s.exampleRepo.FindByID(ctx, id)
+}`,
+
+	"internal/handlers/handlers.go": `package handlers
+
+import (
 	"{{.ProjectName}}/internal/services"
+)
+
+type Handlers struct {
+	Example *ExampleHandler
+}
+
+func New(services *services.Services) *Handlers {
+	return &Handlers{
+		Example: NewExampleHandler(services.Example),
+	}
+}`,
+
+	"internal/handlers/example_handler.go": `package handlers
+
+import (
+	"context"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
+// ExampleService is what ExampleHandler needs from services.ExampleService;
+// tests pass a mock.
+type ExampleService interface {
+	GetExample(ctx context.Context) map[string]interface{}
+}
+
 type ExampleHandler struct {
-	exampleService *services.ExampleService
+	exampleService ExampleService
 }
 
-func NewExampleHandler(exampleService *services.ExampleService) *ExampleHandler {
+func NewExampleHandler(exampleService ExampleService) *ExampleHandler {
 	return &ExampleHandler{
 		exampleService: exampleService,
 	}
 }
 
+// GetExample godoc
+// @Summary Get an example response
+// @Description Returns a sample payload from the service layer
+// @Tags example
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /example [get]
 func (h *ExampleHandler) GetExample(c *gin.Context) {
-	data := h.exampleService.GetExample()
+	data := h.exampleService.GetExample(c.Request.Context())
 	c.JSON(http.StatusOK, data)
 }`,
 }
\ No newline at end of file
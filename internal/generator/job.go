@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+
+	"github.com/adipras/lupettogo/internal/output"
+)
+
+// jobsDir is the package generated jobs are written to.
+const jobsDir = "internal/jobs"
+
+type JobData struct {
+	ProjectName string
+	JobName     string
+	JobTitle    string
+	WithTests   bool
+}
+
+// GenerateJob adds a background job type to a project generated with
+// --with-jobs.
+func GenerateJob(jobName string) error {
+	if jobName == "" {
+		return fmt.Errorf("job name cannot be empty")
+	}
+	if snakeCase(jobName) == "" {
+		return fmt.Errorf("invalid job name %q", jobName)
+	}
+
+	manifest, err := loadOrDetectManifest(".")
+	if err != nil {
+		return err
+	}
+	// Projects without a manifest are recognised by their queue
+	if !manifest.Features.Jobs && !isDir(jobsDir) {
+		return fmt.Errorf("this project has no job queue, generate it with 'lupettogo init --with-jobs'")
+	}
+
+	data := JobData{
+		ProjectName: manifest.Module,
+		JobName:     snakeCase(jobName),
+		JobTitle:    pascalCase(jobName),
+		WithTests:   manifest.Features.Tests,
+	}
+
+	fsys, err := projectTemplateFS(".", manifest)
+	if err != nil {
+		return err
+	}
+
+	files, err := renderJobFiles(fsys, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate job files: %w", err)
+	}
+
+	if err := writeFiles(".", files); err != nil {
+		return fmt.Errorf("failed to generate job files: %w", err)
+	}
+	for _, path := range sortedPaths(files) {
+		output.Printf("📄 Created %s\n", path)
+		output.Created(path)
+	}
+
+	if err := saveBaseSnapshot(".", files); err != nil {
+		return err
+	}
+
+	manifest.SetJob(ManifestJob{
+		Name:  data.JobName,
+		Title: data.JobTitle,
+		Files: manifestFiles(files),
+	})
+	if err := manifest.Save("."); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ManifestName, err)
+	}
+
+	output.Printf("✅ Job '%s' created successfully!\n", jobName)
+	output.Printf("📝 Don't forget to:\n")
+	output.Printf("   - Fill in %sPayload and the Handle method\n", data.JobTitle)
+	output.Printf("   - Register New%s(db) in jobs.All in %s/registry.go\n", data.JobTitle, jobsDir)
+	output.Printf("   - Enqueue it with queue.Enqueue%s(ctx, payload)\n", data.JobTitle)
+	return nil
+}
+
+// renderJobFiles renders the job templates into memory, keyed by the path
+// each file is written to.
+func renderJobFiles(fsys fs.FS, data JobData) (map[string][]byte, error) {
+	files := map[string]string{
+		"job.go.tmpl": filepath.Join(jobsDir, data.JobName+"_job.go"),
+	}
+	if data.WithTests {
+		files["job_test.go.tmpl"] = filepath.Join(jobsDir, data.JobName+"_job_test.go")
+	}
+
+	rendered := map[string][]byte{}
+	for templateFile, outputFile := range files {
+		name := path.Join("jobs", templateFile)
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("template %s not found", templateFile)
+		}
+
+		processed, err := renderFile(name, outputFile, content, data)
+		if err != nil {
+			return nil, err
+		}
+		rendered[outputFile] = processed
+	}
+
+	return rendered, nil
+}
@@ -0,0 +1,96 @@
+package generator
+
+// Job templates, rendered by 'lupettogo generate job' into internal/jobs
+
+var jobTemplates = map[string]string{
+	"job.go.tmpl": `package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// {{.JobTitle}}Type identifies {{.JobTitle}} jobs in the queue.
+const {{.JobTitle}}Type = "{{.JobName}}"
+
+// {{.JobTitle}}Payload is the input of a {{.JobTitle}} job, stored as JSON.
+type {{.JobTitle}}Payload struct {
+	// ID of the record the job works on; replace with the fields you need
+	ID uint ` + "`" + `json:"id"` + "`" + `
+}
+
+type {{.JobTitle}} struct {
+	db *gorm.DB
+}
+
+func New{{.JobTitle}}(db *gorm.DB) *{{.JobTitle}} {
+	return &{{.JobTitle}}{
+		db: db,
+	}
+}
+
+func (j *{{.JobTitle}}) Type() string {
+	return {{.JobTitle}}Type
+}
+
+func (j *{{.JobTitle}}) Handle(ctx context.Context, payload json.RawMessage) error {
+	var p {{.JobTitle}}Payload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return Permanent(fmt.Errorf("invalid %s payload: %w", {{.JobTitle}}Type, err))
+	}
+	if p.ID == 0 {
+		return Permanent(fmt.Errorf("invalid %s payload: id is required", {{.JobTitle}}Type))
+	}
+
+	// TODO: do the work. Return an error to try again later, or wrap it with
+	// Permanent when trying again won't help.
+	return nil
+}
+
+// Enqueue{{.JobTitle}} adds a {{.JobTitle}} job to the queue.
+func (q *Queue) Enqueue{{.JobTitle}}(ctx context.Context, payload {{.JobTitle}}Payload, opts ...EnqueueOption) (*QueuedJob, error) {
+	return q.Enqueue(ctx, {{.JobTitle}}Type, payload, opts...)
+}`,
+
+	"job_test.go.tmpl": `package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test{{.JobTitle}}_Handle(t *testing.T) {
+	payload, err := json.Marshal({{.JobTitle}}Payload{ID: 1})
+	assert.NoError(t, err)
+
+	err = New{{.JobTitle}}(nil).Handle(context.Background(), payload)
+
+	assert.NoError(t, err)
+}
+
+func Test{{.JobTitle}}_Handle_RejectsInvalidPayload(t *testing.T) {
+	tests := map[string]string{
+		"malformed":  ` + "`" + `{"id":` + "`" + `,
+		"missing id": ` + "`" + `{}` + "`" + `,
+	}
+
+	for name, payload := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := New{{.JobTitle}}(nil).Handle(context.Background(), json.RawMessage(payload))
+
+			assert.Error(t, err)
+			assert.True(t, IsPermanent(err), "retrying an invalid payload won't help")
+		})
+	}
+}
+
+func Test{{.JobTitle}}_Type(t *testing.T) {
+	assert.Equal(t, "{{.JobName}}", New{{.JobTitle}}(nil).Type())
+}`,
+}
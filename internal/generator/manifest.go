@@ -0,0 +1,339 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestName is the name of the manifest written to the project root.
+const ManifestName = "lupettogo.yaml"
+
+// Version is the generator version recorded in new manifests. It is set by
+// the CLI at startup.
+var Version = "dev"
+
+// Manifest records how a project was generated so later commands can
+// reproduce and extend it consistently.
+type Manifest struct {
+	GeneratorVersion string            `yaml:"generator_version"`
+	Module           string            `yaml:"module"`
+	DBDriver         string            `yaml:"db_driver"`
+	Features         ManifestFeatures  `yaml:"features"`
+	Template         *ManifestTemplate `yaml:"template,omitempty"`
+	Layout           ManifestLayout    `yaml:"layout"`
+	Files            []ManifestFile    `yaml:"files,omitempty"`
+	Modules          []ManifestModule  `yaml:"modules,omitempty"`
+	Jobs             []ManifestJob     `yaml:"jobs,omitempty"`
+}
+
+type ManifestFeatures struct {
+	Auth      bool `yaml:"auth"`
+	Docker    bool `yaml:"docker"`
+	Tests     bool `yaml:"tests"`
+	Redis     bool `yaml:"redis,omitempty"`
+	RateLimit bool `yaml:"ratelimit,omitempty"`
+	Metrics   bool `yaml:"metrics,omitempty"`
+	Tracing   bool `yaml:"tracing,omitempty"`
+	Jobs      bool `yaml:"jobs,omitempty"`
+	Cache     bool `yaml:"cache,omitempty"`
+	// Kubernetes and Helm record the manifests 'generate deploy' added
+	Kubernetes bool   `yaml:"kubernetes,omitempty"`
+	Helm       bool   `yaml:"helm,omitempty"`
+	CI         string `yaml:"ci,omitempty"`
+}
+
+// ManifestTemplate records the template pack a project was generated from.
+type ManifestTemplate struct {
+	Source   string         `yaml:"source"`
+	Name     string         `yaml:"name"`
+	Checksum string         `yaml:"checksum"`
+	Options  map[string]any `yaml:"options,omitempty"`
+}
+
+// ManifestLayout holds the directories module files are generated into.
+type ManifestLayout struct {
+	Models       string `yaml:"models"`
+	Repositories string `yaml:"repositories"`
+	Services     string `yaml:"services"`
+	Handlers     string `yaml:"handlers"`
+}
+
+type ManifestModule struct {
+	Name   string         `yaml:"name"`
+	Title  string         `yaml:"title"`
+	Fields []Field        `yaml:"fields"`
+	Cache  bool           `yaml:"cache,omitempty"`
+	Files  []ManifestFile `yaml:"files"`
+}
+
+// ManifestJob records a job added with 'lupettogo generate job'.
+type ManifestJob struct {
+	Name  string         `yaml:"name"`
+	Title string         `yaml:"title"`
+	Files []ManifestFile `yaml:"files"`
+}
+
+type ManifestFile struct {
+	Path     string `yaml:"path"`
+	Checksum string `yaml:"checksum"`
+}
+
+// Field is a single model field of a generated module.
+type Field struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Required bool   `yaml:"required,omitempty"`
+	Unique   bool   `yaml:"unique,omitempty"`
+	Default  string `yaml:"default,omitempty"`
+}
+
+func defaultLayout() ManifestLayout {
+	return ManifestLayout{
+		Models:       "internal/models",
+		Repositories: "internal/repositories",
+		Services:     "internal/services",
+		Handlers:     "internal/handlers",
+	}
+}
+
+func newManifest(config ProjectConfig) *Manifest {
+	var template *ManifestTemplate
+	if config.Pack != nil {
+		template = &ManifestTemplate{
+			Source:   config.Pack.manifestSource(config.Name),
+			Name:     config.Pack.Name,
+			Checksum: config.Pack.Checksum,
+			Options:  config.Options,
+		}
+	}
+
+	return &Manifest{
+		GeneratorVersion: Version,
+		Module:           config.Name,
+		DBDriver:         config.DBDriver,
+		Features: ManifestFeatures{
+			Auth:      config.WithAuth,
+			Docker:    config.WithDocker,
+			Tests:     config.WithTests,
+			Redis:     config.WithRedis,
+			RateLimit: config.WithRateLimit,
+			Metrics:   config.WithMetrics,
+			Tracing:   config.WithTracing,
+			Jobs:      config.WithJobs,
+			CI:        config.CI,
+		},
+		Template: template,
+		Layout:   defaultLayout(),
+	}
+}
+
+// LoadManifest reads the manifest from dir. The returned error wraps
+// os.ErrNotExist when the project has no manifest.
+func LoadManifest(dir string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+
+	// Fill in anything an older or hand-edited manifest left out
+	defaults := defaultLayout()
+	if manifest.Layout.Models == "" {
+		manifest.Layout.Models = defaults.Models
+	}
+	if manifest.Layout.Repositories == "" {
+		manifest.Layout.Repositories = defaults.Repositories
+	}
+	if manifest.Layout.Services == "" {
+		manifest.Layout.Services = defaults.Services
+	}
+	if manifest.Layout.Handlers == "" {
+		manifest.Layout.Handlers = defaults.Handlers
+	}
+	if manifest.DBDriver == "" {
+		manifest.DBDriver = "postgres"
+	}
+	if err := ValidateDBDriver(manifest.DBDriver); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+
+	return &manifest, nil
+}
+
+// loadOrDetectManifest returns the project's manifest, or one reconstructed
+// from go.mod for projects generated before manifests existed.
+func loadOrDetectManifest(dir string) (*Manifest, error) {
+	manifest, err := LoadManifest(dir)
+	if err == nil {
+		return manifest, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	projectName, err := ReadModulePath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect project name: %w", err)
+	}
+
+	manifest = newManifest(ProjectConfig{
+		Name:       projectName,
+		DBDriver:   "postgres",
+		WithDocker: true,
+		WithTests:  true,
+	})
+	manifest.GeneratorVersion = "unknown"
+	return manifest, nil
+}
+
+// Save writes the manifest to dir.
+func (m *Manifest) Save(dir string) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by LupettoGo. Commit this file; lupettogo commands read and update it.\n")
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, ManifestName), buf.Bytes(), 0644)
+}
+
+// projectData returns the template data the project was generated with.
+func (m *Manifest) projectData() ProjectData {
+	var options map[string]any
+	if m.Template != nil {
+		options = m.Template.Options
+	}
+	return ProjectData{
+		ProjectName:    m.Module,
+		DBDriver:       m.DBDriver,
+		WithAuth:       m.Features.Auth,
+		WithDocker:     m.Features.Docker,
+		WithTests:      m.Features.Tests,
+		WithRedis:      m.Features.Redis,
+		WithRateLimit:  m.Features.RateLimit,
+		WithMetrics:    m.Features.Metrics,
+		WithTracing:    m.Features.Tracing,
+		WithJobs:       m.Features.Jobs,
+		WithCache:      m.Features.Cache,
+		WithKubernetes: m.Features.Kubernetes,
+		WithHelm:       m.Features.Helm,
+		CI:             m.Features.CI,
+		Options:        options,
+	}.withCatalogue()
+}
+
+// moduleData returns the template data a recorded module was generated with.
+func (m *Manifest) moduleData(module ManifestModule) ModuleData {
+	title := module.Title
+	if title == "" {
+		title = pascalCase(module.Name)
+	}
+	return ModuleData{
+		ProjectName: m.Module,
+		ModuleName:  module.Name,
+		ModuleTitle: title,
+		DBDriver:    m.DBDriver,
+		WithTests:   m.Features.Tests,
+		WithTracing: m.Features.Tracing,
+		WithCache:   module.Cache,
+		Fields:      module.Fields,
+	}
+}
+
+// jobData returns the template data a recorded job was generated with.
+func (m *Manifest) jobData(job ManifestJob) JobData {
+	title := job.Title
+	if title == "" {
+		title = pascalCase(job.Name)
+	}
+	return JobData{
+		ProjectName: m.Module,
+		JobName:     job.Name,
+		JobTitle:    title,
+		WithTests:   m.Features.Tests,
+	}
+}
+
+// checksumOf returns the recorded checksum of path, if any.
+func (m *Manifest) checksumOf(path string) (string, bool) {
+	path = filepath.ToSlash(path)
+	for _, file := range m.Files {
+		if file.Path == path {
+			return file.Checksum, true
+		}
+	}
+	for _, module := range m.Modules {
+		for _, file := range module.Files {
+			if file.Path == path {
+				return file.Checksum, true
+			}
+		}
+	}
+	for _, job := range m.Jobs {
+		for _, file := range job.Files {
+			if file.Path == path {
+				return file.Checksum, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SetModule adds the module to the manifest, replacing an existing entry of
+// the same name.
+func (m *Manifest) SetModule(module ManifestModule) {
+	for i, existing := range m.Modules {
+		if existing.Name == module.Name {
+			m.Modules[i] = module
+			return
+		}
+	}
+	m.Modules = append(m.Modules, module)
+}
+
+// SetJob adds the job to the manifest, replacing an existing entry of the
+// same name.
+func (m *Manifest) SetJob(job ManifestJob) {
+	for i, existing := range m.Jobs {
+		if existing.Name == job.Name {
+			m.Jobs[i] = job
+			return
+		}
+	}
+	m.Jobs = append(m.Jobs, job)
+}
+
+// manifestFiles converts rendered files into sorted manifest entries.
+func manifestFiles(files map[string][]byte) []ManifestFile {
+	entries := make([]ManifestFile, 0, len(files))
+	for path, content := range files {
+		entries = append(entries, ManifestFile{
+			Path:     filepath.ToSlash(path),
+			Checksum: checksum(content),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
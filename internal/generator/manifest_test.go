@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateDBDriver(t *testing.T) {
+	for _, driver := range DBDrivers {
+		if err := ValidateDBDriver(driver); err != nil {
+			t.Errorf("ValidateDBDriver(%q): %v", driver, err)
+		}
+	}
+	for _, driver := range []string{"sqlite", "postgresql", ""} {
+		if err := ValidateDBDriver(driver); err == nil {
+			t.Errorf("ValidateDBDriver(%q) accepted a driver generated projects can't connect to", driver)
+		}
+	}
+}
+
+func TestGenerateProjectRejectsUnknownDriver(t *testing.T) {
+	sandbox(t)
+	err := GenerateProjectWithConfig(ProjectConfig{Name: "myapp", DBDriver: "sqlite"})
+	if err == nil || !strings.Contains(err.Error(), `unsupported database driver "sqlite"`) {
+		t.Fatalf("GenerateProjectWithConfig with sqlite: %v, want an unsupported driver error", err)
+	}
+	if _, err := os.Stat("myapp"); !os.IsNotExist(err) {
+		t.Error("the rejected project was still written")
+	}
+}
+
+func TestLoadManifestRejectsUnknownDriver(t *testing.T) {
+	work := sandbox(t)
+	writeTree(t, work, map[string]string{ManifestName: "module: myapp\ndb_driver: sqlite\n"})
+
+	_, err := LoadManifest(work)
+	if err == nil || !strings.Contains(err.Error(), `unsupported database driver "sqlite"`) {
+		t.Fatalf("LoadManifest with db_driver sqlite: %v, want an unsupported driver error", err)
+	}
+
+	writeTree(t, work, map[string]string{ManifestName: "module: myapp\n"})
+	manifest, err := LoadManifest(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.DBDriver != "postgres" {
+		t.Errorf("DBDriver = %q, want the postgres default", manifest.DBDriver)
+	}
+}
@@ -0,0 +1,150 @@
+package generator
+
+import (
+	"strings"
+)
+
+// mergeLabels name the sides written into conflict markers.
+type mergeLabels struct {
+	Ours   string
+	Base   string
+	Theirs string
+}
+
+// merge3 performs a line-based three-way merge of ours and theirs against
+// their common ancestor base. Regions changed on only one side are taken
+// from that side; regions changed differently on both sides are written
+// with conflict markers. It reports whether any conflicts were written.
+func merge3(base, ours, theirs string, labels mergeLabels) (string, bool) {
+	baseLines := splitLines(base)
+	ourLines := splitLines(ours)
+	theirLines := splitLines(theirs)
+
+	matchOurs := lcsMatch(baseLines, ourLines)
+	matchTheirs := lcsMatch(baseLines, theirLines)
+
+	var out strings.Builder
+	conflict := false
+	z, a, b := 0, 0, 0
+
+	emitChunk := func(zEnd, aEnd, bEnd int) {
+		baseChunk := baseLines[z:zEnd]
+		ourChunk := ourLines[a:aEnd]
+		theirChunk := theirLines[b:bEnd]
+
+		switch {
+		case equalLines(ourChunk, baseChunk):
+			writeLines(&out, theirChunk)
+		case equalLines(theirChunk, baseChunk), equalLines(ourChunk, theirChunk):
+			writeLines(&out, ourChunk)
+		default:
+			conflict = true
+			out.WriteString("<<<<<<< " + labels.Ours + "\n")
+			writeLines(&out, ourChunk)
+			out.WriteString("||||||| " + labels.Base + "\n")
+			writeLines(&out, baseChunk)
+			out.WriteString("=======\n")
+			writeLines(&out, theirChunk)
+			out.WriteString(">>>>>>> " + labels.Theirs + "\n")
+		}
+	}
+
+	// Lines of base kept by both sides are sync points; everything between
+	// two sync points is resolved as a single chunk.
+	for zi := range baseLines {
+		ai, bi := matchOurs[zi], matchTheirs[zi]
+		if ai < 0 || bi < 0 {
+			continue
+		}
+		emitChunk(zi, ai, bi)
+		out.WriteString(baseLines[zi])
+		z, a, b = zi+1, ai+1, bi+1
+	}
+	emitChunk(len(baseLines), len(ourLines), len(theirLines))
+
+	// splitLines added a newline to unterminated last lines; a missing final
+	// newline is merged like any other single-line change.
+	merged := out.String()
+	endsWithNewline := hasFinalNewline(ours)
+	if endsWithNewline == hasFinalNewline(base) {
+		endsWithNewline = hasFinalNewline(theirs)
+	}
+	if !endsWithNewline && !conflict {
+		merged = strings.TrimSuffix(merged, "\n")
+	}
+	return merged, conflict
+}
+
+func hasFinalNewline(s string) bool {
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+// splitLines splits s after each newline. A final line without a newline is
+// given one so that chunks from different sides can be joined safely.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+	return lines
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lcsMatch returns, for every line of a, the index of the line of b it is
+// paired with in a longest common subsequence, or -1.
+func lcsMatch(a, b []string) []int {
+	n, m := len(a), len(b)
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	match := make([]int, n)
+	for i := range match {
+		match[i] = -1
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			match[i] = j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
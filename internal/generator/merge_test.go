@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testLabels = mergeLabels{Ours: "yours", Base: "base", Theirs: "theirs"}
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		ours     string
+		theirs   string
+		want     string
+		conflict bool
+	}{
+		{
+			name:   "non-overlapping changes",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "a\nB\nc\nd\ne\n",
+			theirs: "a\nb\nc\nD\ne\n",
+			want:   "a\nB\nc\nD\ne\n",
+		},
+		{
+			name:   "insertions at both ends",
+			base:   "a\nb\n",
+			ours:   "first\na\nb\n",
+			theirs: "a\nb\nlast\n",
+			want:   "first\na\nb\nlast\n",
+		},
+		{
+			name:   "deletion on one side",
+			base:   "a\nb\nc\n",
+			ours:   "a\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nc\n",
+		},
+		{
+			name:   "identical changes on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nX\nY\nc\n",
+			theirs: "a\nX\nY\nc\n",
+			want:   "a\nX\nY\nc\n",
+		},
+		{
+			name:     "overlapping changes",
+			base:     "a\nb\nc\n",
+			ours:     "a\nmine\nc\n",
+			theirs:   "a\nnew\nc\n",
+			want:     "a\n<<<<<<< yours\nmine\n||||||| base\nb\n=======\nnew\n>>>>>>> theirs\nc\n",
+			conflict: true,
+		},
+		{
+			name:     "no common ancestor",
+			base:     "",
+			ours:     "mine\n",
+			theirs:   "new\n",
+			want:     "<<<<<<< yours\nmine\n||||||| base\n=======\nnew\n>>>>>>> theirs\n",
+			conflict: true,
+		},
+		{
+			name:   "no trailing newline anywhere",
+			base:   "a\nb",
+			ours:   "x\nb",
+			theirs: "a\nb\nc",
+			want:   "x\nb\nc",
+		},
+		{
+			name:   "their side adds the trailing newline",
+			base:   "a\nb",
+			ours:   "x\nb",
+			theirs: "a\nb\n",
+			want:   "x\nb\n",
+		},
+		{
+			name:   "our side drops the trailing newline",
+			base:   "a\nb\n",
+			ours:   "a\nb",
+			theirs: "A\nb\n",
+			want:   "A\nb",
+		},
+		{
+			name:     "conflict on an unterminated last line",
+			base:     "a\nb",
+			ours:     "a\nmine",
+			theirs:   "a\nnew",
+			want:     "a\n<<<<<<< yours\nmine\n||||||| base\nb\n=======\nnew\n>>>>>>> theirs\n",
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := merge3(tt.base, tt.ours, tt.theirs, testLabels)
+			if got != tt.want || conflict != tt.conflict {
+				t.Errorf("merge3() = %q, %v\nwant %q, %v", got, conflict, tt.want, tt.conflict)
+			}
+		})
+	}
+}
+
+func TestLCSMatch(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want []int
+	}{
+		{[]string{"a", "b", "c"}, []string{"a", "b", "c"}, []int{0, 1, 2}},
+		{[]string{"a", "b", "c"}, []string{"a", "c"}, []int{0, -1, 1}},
+		{[]string{"a", "c"}, []string{"x", "a", "b", "c"}, []int{1, 3}},
+		{[]string{"a", "b"}, []string{"c", "d"}, []int{-1, -1}},
+		{[]string{"a"}, nil, []int{-1}},
+		{nil, []string{"a"}, []int{}},
+	}
+	for _, tt := range tests {
+		if got := lcsMatch(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lcsMatch(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a\nb\n", []string{"a\n", "b\n"}},
+		{"a\nb", []string{"a\n", "b\n"}},
+		{"\n", []string{"\n"}},
+	}
+	for _, tt := range tests {
+		if got := splitLines(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitLines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
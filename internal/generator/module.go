@@ -2,85 +2,245 @@ package generator
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/adipras/lupettogo/internal/output"
 )
 
 type ModuleData struct {
 	ProjectName string
 	ModuleName  string
 	ModuleTitle string
+	DBDriver    string
+	WithTests   bool
+	WithTracing bool
+	WithCache   bool
+	Fields      []Field
+}
+
+type ModuleOptions struct {
+	// Fields of the model; defaultModuleFields when empty
+	Fields []Field
+	// Cache wraps the repository in a cache-aside decorator
+	Cache bool
+	// Force overwrites module files that already exist
+	Force bool
 }
 
-func GenerateModule(moduleName string) error {
+// defaultModuleFields are used when no --fields are given.
+var defaultModuleFields = []Field{
+	{Name: "name", Type: "string", Required: true},
+	{Name: "status", Type: "string", Default: "active"},
+}
+
+func GenerateModule(moduleName string, opts ModuleOptions) error {
 	if moduleName == "" {
 		return fmt.Errorf("module name cannot be empty")
 	}
+	if snakeCase(moduleName) == "" {
+		return fmt.Errorf("invalid module name %q", moduleName)
+	}
 
-	// Get current project name from go.mod
-	projectName, err := getCurrentProjectName()
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = defaultModuleFields
+	}
+
+	// Project settings come from the manifest, or go.mod for older projects
+	manifest, err := loadOrDetectManifest(".")
 	if err != nil {
-		return fmt.Errorf("failed to detect project name: %w", err)
+		return err
 	}
 
 	data := ModuleData{
-		ProjectName: projectName,
-		ModuleName:  strings.ToLower(moduleName),
-		ModuleTitle: strings.Title(moduleName),
+		ProjectName: manifest.Module,
+		ModuleName:  snakeCase(moduleName),
+		ModuleTitle: pascalCase(moduleName),
+		DBDriver:    manifest.DBDriver,
+		WithTests:   manifest.Features.Tests,
+		WithTracing: manifest.Features.Tracing,
+		WithCache:   opts.Cache,
+		Fields:      fields,
+	}
+
+	fsys, err := projectTemplateFS(".", manifest)
+	if err != nil {
+		return err
+	}
+
+	files, err := renderModuleFiles(fsys, data, manifest.Layout)
+	if err != nil {
+		return fmt.Errorf("failed to generate module files: %w", err)
 	}
 
-	if err := generateModuleFiles(data); err != nil {
+	// Module files are edited by hand, don't replace them by accident
+	if !opts.Force {
+		for _, path := range sortedPaths(files) {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+			}
+		}
+	}
+
+	// The cache package is generated for the first module that uses it
+	var cacheDeps []Dependency
+	if data.WithCache && !manifest.Features.Cache && !isDir(cacheDir) {
+		cacheDeps, err = generateCachePackage(fsys, manifest)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeFiles(".", files); err != nil {
 		return fmt.Errorf("failed to generate module files: %w", err)
 	}
+	for _, path := range sortedPaths(files) {
+		output.Printf("📄 Created %s\n", path)
+		output.Created(path)
+	}
 
-	fmt.Printf("✅ Module '%s' created successfully!\n", moduleName)
-	fmt.Printf("📝 Don't forget to:\n")
-	fmt.Printf("   - Add the new model to database migrations\n")
-	fmt.Printf("   - Register the handler in server routes\n")
-	fmt.Printf("   - Update services.go and handlers.go\n")
+	if err := saveBaseSnapshot(".", files); err != nil {
+		return err
+	}
+
+	manifest.SetModule(ManifestModule{
+		Name:   data.ModuleName,
+		Title:  data.ModuleTitle,
+		Fields: data.Fields,
+		Cache:  data.WithCache,
+		Files:  manifestFiles(files),
+	})
+	if err := manifest.Save("."); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ManifestName, err)
+	}
+
+	downloaded, err := requireDependencies(".", cacheDeps)
+	if err != nil {
+		return err
+	}
+
+	output.Printf("✅ Module '%s' created successfully!\n", moduleName)
+	output.Printf("📝 Don't forget to:\n")
+	output.Printf("   - Add the new model to database migrations\n")
+	output.Printf("   - Register the handler in server routes\n")
+	output.Printf("   - Update services.go and handlers.go\n")
+	if data.WithCache {
+		output.Printf("   - Wrap the repository with repositories.NewCached%sRepository, sharing one cache.New(cfg) from cache.LoadConfig()\n", data.ModuleTitle)
+	}
+	if !downloaded {
+		output.Printf("   - Run 'go mod tidy' to download the cache's dependencies\n")
+	}
+	output.Printf("   - Run 'lupettogo openapi' to refresh the API spec\n")
 	return nil
 }
 
-func generateModuleFiles(data ModuleData) error {
+// renderModuleFiles renders the module templates into memory, keyed by the
+// path each file is written to.
+func renderModuleFiles(fsys fs.FS, data ModuleData, layout ManifestLayout) (map[string][]byte, error) {
 	files := map[string]string{
-		"model.go.tmpl":      fmt.Sprintf("internal/models/%s.go", data.ModuleName),
-		"repository.go.tmpl": fmt.Sprintf("internal/repositories/%s_repository.go", data.ModuleName),
-		"service.go.tmpl":    fmt.Sprintf("internal/services/%s_service.go", data.ModuleName),
-		"handler.go.tmpl":    fmt.Sprintf("internal/handlers/%s_handler.go", data.ModuleName),
+		"model.go.tmpl":      filepath.Join(layout.Models, data.ModuleName+".go"),
+		"repository.go.tmpl": filepath.Join(layout.Repositories, data.ModuleName+"_repository.go"),
+		"service.go.tmpl":    filepath.Join(layout.Services, data.ModuleName+"_service.go"),
+		"handler.go.tmpl":    filepath.Join(layout.Handlers, data.ModuleName+"_handler.go"),
+	}
+	if data.WithTests {
+		files["handler_test.go.tmpl"] = filepath.Join(layout.Handlers, data.ModuleName+"_handler_test.go")
+	}
+	if data.WithCache {
+		files["cached_repository.go.tmpl"] = filepath.Join(layout.Repositories, data.ModuleName+"_repository_cache.go")
+		if data.WithTests {
+			files["cached_repository_test.go.tmpl"] = filepath.Join(layout.Repositories, data.ModuleName+"_repository_cache_test.go")
+		}
 	}
 
+	rendered := map[string][]byte{}
 	for templateFile, outputFile := range files {
 		// Get template content
-		content, exists := moduleTemplates[templateFile]
-		if !exists {
-			return fmt.Errorf("template %s not found", templateFile)
+		name := path.Join("modules", templateFile)
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("template %s not found", templateFile)
 		}
 
-		// Create output directory if it doesn't exist
-		outputDir := filepath.Dir(outputFile)
-		if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
-			return err
+		processed, err := renderFile(name, outputFile, content, data)
+		if err != nil {
+			return nil, err
 		}
+		rendered[outputFile] = processed
+	}
+
+	return rendered, nil
+}
 
-		// Replace placeholders
-		processed := strings.ReplaceAll(content, "__module__", data.ModuleName)
-		processed = strings.ReplaceAll(processed, "__Module__", data.ModuleTitle)
-		processed = strings.ReplaceAll(processed, "{{.ProjectName}}", data.ProjectName)
+// ParseFields parses a field list such as
+// "title:string:required,price:float,sku:string:unique,status:string:default=draft".
+func ParseFields(spec string) ([]Field, error) {
+	var fields []Field
+	seen := map[string]bool{"id": true, "created_at": true, "updated_at": true, "deleted_at": true}
 
-		// Write output file
-		if err := os.WriteFile(outputFile, []byte(processed), 0644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
 		}
 
-		fmt.Printf("📄 Created %s\n", outputFile)
+		parts := strings.Split(item, ":")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid field %q, expected name:type[:modifier...]", item)
+		}
+
+		field := Field{Name: snakeCase(parts[0]), Type: strings.ToLower(parts[1])}
+		if field.Name == "" {
+			return nil, fmt.Errorf("invalid field name in %q", item)
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("duplicate or reserved field %q", field.Name)
+		}
+		seen[field.Name] = true
+		if _, ok := fieldTypes[field.Type]; !ok {
+			return nil, fmt.Errorf("unknown type %q for field %s (supported: %s)", field.Type, field.Name, supportedFieldTypes())
+		}
+
+		for _, modifier := range parts[2:] {
+			switch {
+			case modifier == "required":
+				field.Required = true
+			case modifier == "unique":
+				field.Unique = true
+			case strings.HasPrefix(modifier, "default="):
+				field.Default = strings.TrimPrefix(modifier, "default=")
+				// The value ends up inside the struct tag of the model
+				if strings.ContainsAny(field.Default, ";\"`") {
+					return nil, fmt.Errorf("default value %q of field %s cannot contain ;, \" or `", field.Default, field.Name)
+				}
+			default:
+				return nil, fmt.Errorf("unknown modifier %q for field %s", modifier, field.Name)
+			}
+		}
+
+		fields = append(fields, field)
 	}
 
-	return nil
+	return fields, nil
+}
+
+func supportedFieldTypes() string {
+	types := make([]string, 0, len(fieldTypes))
+	for t := range fieldTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ", ")
 }
 
-func getCurrentProjectName() (string, error) {
-	content, err := os.ReadFile("go.mod")
+// ReadModulePath returns the module path declared in dir/go.mod.
+func ReadModulePath(dir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,6 @@
 package generator
 
-// Module generation templates
+// Module generation templates, rendered with ModuleData
 
 var moduleTemplates = map[string]string{
 	"model.go.tmpl": `package models
@@ -11,151 +11,201 @@ import (
 	"gorm.io/gorm"
 )
 
-type __Module__ struct {
+type {{.ModuleTitle}} struct {
 	ID        uint           ` + "`" + `json:"id" gorm:"primarykey"` + "`" + `
-	Name      string         ` + "`" + `json:"name" gorm:"not null" validate:"required"` + "`" + `
-	Status    string         ` + "`" + `json:"status" gorm:"default:active"` + "`" + `
+{{- range .Fields}}
+	{{pascal .Name}} {{goType .Type}} ` + "`" + `json:"{{snake .Name}}" gorm:"type:{{sqlType $.DBDriver .Type}}{{if .Required}};not null{{end}}{{if .Unique}};uniqueIndex{{end}}{{if .Default}};default:{{.Default}}{{end}}"{{if .Required}} validate:"required"{{end}}` + "`" + `
+{{- end}}
 	CreatedAt time.Time      ` + "`" + `json:"created_at"` + "`" + `
 	UpdatedAt time.Time      ` + "`" + `json:"updated_at"` + "`" + `
 	DeletedAt gorm.DeletedAt ` + "`" + `json:"-" gorm:"index"` + "`" + `
 }
 
-func (__Module__) TableName() string {
-	return "__module__s"
+func ({{.ModuleTitle}}) TableName() string {
+	return "{{snake .ModuleName | pluralize}}"
 }`,
 
-	"repository.go.tmpl": `package repositories
+	"repository.go.tmpl": `{{- $var := camel .ModuleName}}{{$vars := pluralize $var -}}
+package repositories
 
 import (
+	"context"
+	"fmt"
+
 	"{{.ProjectName}}/internal/models"
 	"gorm.io/gorm"
 )
 
-type __Module__Repository struct {
+// {{$var}}Columns lists the columns FindByField may filter on
+var {{$var}}Columns = map[string]bool{
+	"id": true,
+{{- range .Fields}}
+	"{{snake .Name}}": true,
+{{- end}}
+}
+
+type {{.ModuleTitle}}Repository struct {
 	db *gorm.DB
 }
 
-func New__Module__Repository(db *gorm.DB) *__Module__Repository {
-	return &__Module__Repository{
+func New{{.ModuleTitle}}Repository(db *gorm.DB) *{{.ModuleTitle}}Repository {
+	return &{{.ModuleTitle}}Repository{
 		db: db,
 	}
 }
 
-func (r *__Module__Repository) FindAll() ([]*models.__Module__, error) {
-	var __module__s []*models.__Module__
-	err := r.db.Find(&__module__s).Error
-	return __module__s, err
+func (r *{{.ModuleTitle}}Repository) FindAll(ctx context.Context) ([]*models.{{.ModuleTitle}}, error) {
+	var {{$vars}} []*models.{{.ModuleTitle}}
+	err := r.db.WithContext(ctx).Find(&{{$vars}}).Error
+	return {{$vars}}, err
 }
 
-func (r *__Module__Repository) FindByID(id uint) (*models.__Module__, error) {
-	var __module__ models.__Module__
-	err := r.db.First(&__module__, id).Error
+func (r *{{.ModuleTitle}}Repository) FindByID(ctx context.Context, id uint) (*models.{{.ModuleTitle}}, error) {
+	var {{$var}} models.{{.ModuleTitle}}
+	err := r.db.WithContext(ctx).First(&{{$var}}, id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &__module__, nil
+	return &{{$var}}, nil
 }
 
-func (r *__Module__Repository) Create(__module__ *models.__Module__) (*models.__Module__, error) {
-	err := r.db.Create(__module__).Error
-	return __module__, err
+func (r *{{.ModuleTitle}}Repository) Create(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error) {
+	err := r.db.WithContext(ctx).Create({{$var}}).Error
+	return {{$var}}, err
 }
 
-func (r *__Module__Repository) Update(__module__ *models.__Module__) (*models.__Module__, error) {
-	err := r.db.Save(__module__).Error
-	return __module__, err
+func (r *{{.ModuleTitle}}Repository) Update(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error) {
+	err := r.db.WithContext(ctx).Save({{$var}}).Error
+	return {{$var}}, err
 }
 
-func (r *__Module__Repository) Delete(id uint) error {
-	return r.db.Delete(&models.__Module__{}, id).Error
+func (r *{{.ModuleTitle}}Repository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.{{.ModuleTitle}}{}, id).Error
 }
 
-func (r *__Module__Repository) FindByField(field string, value interface{}) ([]*models.__Module__, error) {
-	var __module__s []*models.__Module__
-	err := r.db.Where(field+" = ?", value).Find(&__module__s).Error
-	return __module__s, err
+func (r *{{.ModuleTitle}}Repository) FindByField(ctx context.Context, field string, value interface{}) ([]*models.{{.ModuleTitle}}, error) {
+	if !{{$var}}Columns[field] {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+
+	var {{$vars}} []*models.{{.ModuleTitle}}
+	err := r.db.WithContext(ctx).Where(field+" = ?", value).Find(&{{$vars}}).Error
+	return {{$vars}}, err
 }`,
 
-	"service.go.tmpl": `package services
+	"service.go.tmpl": `{{- $var := camel .ModuleName}}{{$label := lower .ModuleTitle -}}
+package services
 
 import (
+	"context"
 	"errors"
 
 	"{{.ProjectName}}/internal/models"
-	"{{.ProjectName}}/internal/repositories"
 )
 
-type __Module__Service struct {
-	__module__Repo *repositories.__Module__Repository
+// {{.ModuleTitle}}Repository is the storage {{.ModuleTitle}}Service needs, so a cached
+// repository or a mock can stand in for repositories.{{.ModuleTitle}}Repository.
+type {{.ModuleTitle}}Repository interface {
+	FindAll(ctx context.Context) ([]*models.{{.ModuleTitle}}, error)
+	FindByID(ctx context.Context, id uint) (*models.{{.ModuleTitle}}, error)
+	Create(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error)
+	Update(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type {{.ModuleTitle}}Service struct {
+	{{$var}}Repo {{.ModuleTitle}}Repository
 }
 
-func New__Module__Service(__module__Repo *repositories.__Module__Repository) *__Module__Service {
-	return &__Module__Service{
-		__module__Repo: __module__Repo,
+func New{{.ModuleTitle}}Service({{$var}}Repo {{.ModuleTitle}}Repository) *{{.ModuleTitle}}Service {
+	return &{{.ModuleTitle}}Service{
+		{{$var}}Repo: {{$var}}Repo,
 	}
 }
 
-func (s *__Module__Service) GetAll__Module__s() ([]*models.__Module__, error) {
-	return s.__module__Repo.FindAll()
+func (s *{{.ModuleTitle}}Service) GetAll{{pluralize .ModuleTitle}}(ctx context.Context) ([]*models.{{.ModuleTitle}}, error) {
+{{- if .WithTracing}}
+	ctx, span := tracer.Start(ctx, "{{.ModuleTitle}}Service.GetAll{{pluralize .ModuleTitle}}")
+	defer span.End()
+{{end}}
+	return s.{{$var}}Repo.FindAll(ctx)
 }
 
-func (s *__Module__Service) Get__Module__ByID(id uint) (*models.__Module__, error) {
-	return s.__module__Repo.FindByID(id)
+func (s *{{.ModuleTitle}}Service) Get{{.ModuleTitle}}ByID(ctx context.Context, id uint) (*models.{{.ModuleTitle}}, error) {
+{{- if .WithTracing}}
+	ctx, span := tracer.Start(ctx, "{{.ModuleTitle}}Service.Get{{.ModuleTitle}}ByID")
+	defer span.End()
+{{end}}
+	return s.{{$var}}Repo.FindByID(ctx, id)
 }
 
-func (s *__Module__Service) Create__Module__(__module__ *models.__Module__) (*models.__Module__, error) {
+func (s *{{.ModuleTitle}}Service) Create{{.ModuleTitle}}(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error) {
+{{- if .WithTracing}}
+	ctx, span := tracer.Start(ctx, "{{.ModuleTitle}}Service.Create{{.ModuleTitle}}")
+	defer span.End()
+{{end}}
 	// Add business logic validation here
-	if err := s.validate__Module__(__module__); err != nil {
+	if err := s.validate{{.ModuleTitle}}({{$var}}); err != nil {
 		return nil, err
 	}
 
-	return s.__module__Repo.Create(__module__)
+	return s.{{$var}}Repo.Create(ctx, {{$var}})
 }
 
-func (s *__Module__Service) Update__Module__(__module__ *models.__Module__) (*models.__Module__, error) {
-	// Check if __module__ exists
-	existing, err := s.__module__Repo.FindByID(__module__.ID)
+func (s *{{.ModuleTitle}}Service) Update{{.ModuleTitle}}(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error) {
+{{- if .WithTracing}}
+	ctx, span := tracer.Start(ctx, "{{.ModuleTitle}}Service.Update{{.ModuleTitle}}")
+	defer span.End()
+{{end}}
+	// Check if {{$label}} exists
+	existing, err := s.{{$var}}Repo.FindByID(ctx, {{$var}}.ID)
 	if err != nil {
 		return nil, err
 	}
 	if existing == nil {
-		return nil, errors.New("__module__ not found")
+		return nil, errors.New("{{$label}} not found")
 	}
 
 	// Add business logic validation here
-	if err := s.validate__Module__(__module__); err != nil {
+	if err := s.validate{{.ModuleTitle}}({{$var}}); err != nil {
 		return nil, err
 	}
 
-	return s.__module__Repo.Update(__module__)
+	return s.{{$var}}Repo.Update(ctx, {{$var}})
 }
 
-func (s *__Module__Service) Delete__Module__(id uint) error {
-	// Check if __module__ exists
-	existing, err := s.__module__Repo.FindByID(id)
+func (s *{{.ModuleTitle}}Service) Delete{{.ModuleTitle}}(ctx context.Context, id uint) error {
+{{- if .WithTracing}}
+	ctx, span := tracer.Start(ctx, "{{.ModuleTitle}}Service.Delete{{.ModuleTitle}}")
+	defer span.End()
+{{end}}
+	// Check if {{$label}} exists
+	existing, err := s.{{$var}}Repo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 	if existing == nil {
-		return errors.New("__module__ not found")
+		return errors.New("{{$label}} not found")
 	}
 
-	return s.__module__Repo.Delete(id)
+	return s.{{$var}}Repo.Delete(ctx, id)
 }
 
-func (s *__Module__Service) validate__Module__(__module__ *models.__Module__) error {
+func (s *{{.ModuleTitle}}Service) validate{{.ModuleTitle}}({{$var}} *models.{{.ModuleTitle}}) error {
+{{- range .Fields}}{{if and .Required (eq .Type "string" "text")}}
+	if {{$var}}.{{pascal .Name}} == "" {
+		return errors.New("{{snake .Name}} is required")
+	}
+{{- end}}{{end}}
 	// Add your business logic validation here
-	// Example:
-	// if __module__.Name == "" {
-	//     return errors.New("name is required")
-	// }
 	return nil
 }`,
 
-	"handler.go.tmpl": `package handlers
+	"handler.go.tmpl": `{{- $var := camel .ModuleName}}{{$path := kebab .ModuleName | pluralize}}{{$tag := snake .ModuleName | pluralize}}{{$label := lower .ModuleTitle}}{{$labels := pluralize $label -}}
+package handlers
 
 import (
 	"net/http"
@@ -166,44 +216,44 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-type __Module__Handler struct {
-	__module__Service *services.__Module__Service
+type {{.ModuleTitle}}Handler struct {
+	{{$var}}Service *services.{{.ModuleTitle}}Service
 }
 
-func New__Module__Handler(__module__Service *services.__Module__Service) *__Module__Handler {
-	return &__Module__Handler{
-		__module__Service: __module__Service,
+func New{{.ModuleTitle}}Handler({{$var}}Service *services.{{.ModuleTitle}}Service) *{{.ModuleTitle}}Handler {
+	return &{{.ModuleTitle}}Handler{
+		{{$var}}Service: {{$var}}Service,
 	}
 }
 
-// Get__Module__s godoc
-// @Summary Get all __module__s
-// @Description Get a list of all __module__s
-// @Tags __module__s
+// Get{{pluralize .ModuleTitle}} godoc
+// @Summary Get all {{$labels}}
+// @Description Get a list of all {{$labels}}
+// @Tags {{$tag}}
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.__Module__
-// @Router /__module__s [get]
-func (h *__Module__Handler) Get__Module__s(c *gin.Context) {
-	__module__s, err := h.__module__Service.GetAll__Module__s()
+// @Success 200 {array} models.{{.ModuleTitle}}
+// @Router /{{$path}} [get]
+func (h *{{.ModuleTitle}}Handler) Get{{pluralize .ModuleTitle}}(c *gin.Context) {
+	{{pluralize $var}}, err := h.{{$var}}Service.GetAll{{pluralize .ModuleTitle}}(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, __module__s)
+	c.JSON(http.StatusOK, {{pluralize $var}})
 }
 
-// Get__Module__ godoc
-// @Summary Get a __module__ by ID
-// @Description Get a single __module__ by its ID
-// @Tags __module__s
+// Get{{.ModuleTitle}} godoc
+// @Summary Get a {{$label}} by ID
+// @Description Get a single {{$label}} by its ID
+// @Tags {{$tag}}
 // @Accept json
 // @Produce json
-// @Param id path int true "__Module__ ID"
-// @Success 200 {object} models.__Module__
+// @Param id path int true "{{.ModuleTitle}} ID"
+// @Success 200 {object} models.{{.ModuleTitle}}
 // @Failure 404 {object} map[string]string
-// @Router /__module__s/{id} [get]
-func (h *__Module__Handler) Get__Module__(c *gin.Context) {
+// @Router /{{$path}}/{id} [get]
+func (h *{{.ModuleTitle}}Handler) Get{{.ModuleTitle}}(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -211,54 +261,54 @@ func (h *__Module__Handler) Get__Module__(c *gin.Context) {
 		return
 	}
 
-	__module__, err := h.__module__Service.Get__Module__ByID(uint(id))
+	{{$var}}, err := h.{{$var}}Service.Get{{.ModuleTitle}}ByID(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "__Module__ not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "{{.ModuleTitle}} not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, __module__)
+	c.JSON(http.StatusOK, {{$var}})
 }
 
-// Create__Module__ godoc
-// @Summary Create a new __module__
-// @Description Create a new __module__ with the given data
-// @Tags __module__s
+// Create{{.ModuleTitle}} godoc
+// @Summary Create a new {{$label}}
+// @Description Create a new {{$label}} with the given data
+// @Tags {{$tag}}
 // @Accept json
 // @Produce json
-// @Param __module__ body models.__Module__ true "__Module__ object"
-// @Success 201 {object} models.__Module__
+// @Param {{$var}} body models.{{.ModuleTitle}} true "{{.ModuleTitle}} object"
+// @Success 201 {object} models.{{.ModuleTitle}}
 // @Failure 400 {object} map[string]string
-// @Router /__module__s [post]
-func (h *__Module__Handler) Create__Module__(c *gin.Context) {
-	var __module__ models.__Module__
-	if err := c.ShouldBindJSON(&__module__); err != nil {
+// @Router /{{$path}} [post]
+func (h *{{.ModuleTitle}}Handler) Create{{.ModuleTitle}}(c *gin.Context) {
+	var {{$var}} models.{{.ModuleTitle}}
+	if err := c.ShouldBindJSON(&{{$var}}); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	created__Module__, err := h.__module__Service.Create__Module__(&__module__)
+	created{{.ModuleTitle}}, err := h.{{$var}}Service.Create{{.ModuleTitle}}(c.Request.Context(), &{{$var}})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, created__Module__)
+	c.JSON(http.StatusCreated, created{{.ModuleTitle}})
 }
 
-// Update__Module__ godoc
-// @Summary Update a __module__
-// @Description Update a __module__ with the given data
-// @Tags __module__s
+// Update{{.ModuleTitle}} godoc
+// @Summary Update a {{$label}}
+// @Description Update a {{$label}} with the given data
+// @Tags {{$tag}}
 // @Accept json
 // @Produce json
-// @Param id path int true "__Module__ ID"
-// @Param __module__ body models.__Module__ true "__Module__ object"
-// @Success 200 {object} models.__Module__
+// @Param id path int true "{{.ModuleTitle}} ID"
+// @Param {{$var}} body models.{{.ModuleTitle}} true "{{.ModuleTitle}} object"
+// @Success 200 {object} models.{{.ModuleTitle}}
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
-// @Router /__module__s/{id} [put]
-func (h *__Module__Handler) Update__Module__(c *gin.Context) {
+// @Router /{{$path}}/{id} [put]
+func (h *{{.ModuleTitle}}Handler) Update{{.ModuleTitle}}(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -266,33 +316,33 @@ func (h *__Module__Handler) Update__Module__(c *gin.Context) {
 		return
 	}
 
-	var __module__ models.__Module__
-	if err := c.ShouldBindJSON(&__module__); err != nil {
+	var {{$var}} models.{{.ModuleTitle}}
+	if err := c.ShouldBindJSON(&{{$var}}); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	__module__.ID = uint(id)
-	updated__Module__, err := h.__module__Service.Update__Module__(&__module__)
+	{{$var}}.ID = uint(id)
+	updated{{.ModuleTitle}}, err := h.{{$var}}Service.Update{{.ModuleTitle}}(c.Request.Context(), &{{$var}})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, updated__Module__)
+	c.JSON(http.StatusOK, updated{{.ModuleTitle}})
 }
 
-// Delete__Module__ godoc
-// @Summary Delete a __module__
-// @Description Delete a __module__ by ID
-// @Tags __module__s
+// Delete{{.ModuleTitle}} godoc
+// @Summary Delete a {{$label}}
+// @Description Delete a {{$label}} by ID
+// @Tags {{$tag}}
 // @Accept json
 // @Produce json
-// @Param id path int true "__Module__ ID"
+// @Param id path int true "{{.ModuleTitle}} ID"
 // @Success 204
 // @Failure 404 {object} map[string]string
-// @Router /__module__s/{id} [delete]
-func (h *__Module__Handler) Delete__Module__(c *gin.Context) {
+// @Router /{{$path}}/{id} [delete]
+func (h *{{.ModuleTitle}}Handler) Delete{{.ModuleTitle}}(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -300,11 +350,277 @@ func (h *__Module__Handler) Delete__Module__(c *gin.Context) {
 		return
 	}
 
-	if err := h.__module__Service.Delete__Module__(uint(id)); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "__Module__ not found"})
+	if err := h.{{$var}}Service.Delete{{.ModuleTitle}}(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "{{.ModuleTitle}} not found"})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }`,
-}
\ No newline at end of file
+
+	"cached_repository.go.tmpl": `{{- $var := camel .ModuleName -}}
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"{{.ProjectName}}/internal/cache"
+	"{{.ProjectName}}/internal/models"
+)
+
+// {{.ModuleTitle}}Store is the storage Cached{{.ModuleTitle}}Repository reads
+// through, usually a *{{.ModuleTitle}}Repository.
+type {{.ModuleTitle}}Store interface {
+	FindAll(ctx context.Context) ([]*models.{{.ModuleTitle}}, error)
+	FindByID(ctx context.Context, id uint) (*models.{{.ModuleTitle}}, error)
+	Create(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error)
+	Update(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+// Cached{{.ModuleTitle}}Repository caches FindByID in front of a
+// {{.ModuleTitle}}Store. Create, Update and Delete evict the record they
+// change once the store has; a failed eviction is logged and the record may
+// be stale until its entry expires. FindAll isn't cached.
+type Cached{{.ModuleTitle}}Repository struct {
+	{{.ModuleTitle}}Store
+	cache *cache.Cache
+}
+
+func NewCached{{.ModuleTitle}}Repository(store {{.ModuleTitle}}Store, c *cache.Cache) *Cached{{.ModuleTitle}}Repository {
+	return &Cached{{.ModuleTitle}}Repository{
+		{{.ModuleTitle}}Store: store,
+		cache:     c,
+	}
+}
+
+// {{$var}}CacheKey is the key the {{.ModuleTitle}} with id is cached under.
+func {{$var}}CacheKey(id uint) string {
+	return fmt.Sprintf("{{.ModuleName}}:%d", id)
+}
+
+func (r *Cached{{.ModuleTitle}}Repository) FindByID(ctx context.Context, id uint) (*models.{{.ModuleTitle}}, error) {
+	return cache.GetOrLoad(ctx, r.cache, {{$var}}CacheKey(id), func(ctx context.Context) (*models.{{.ModuleTitle}}, error) {
+		return r.{{.ModuleTitle}}Store.FindByID(ctx, id)
+	})
+}
+
+func (r *Cached{{.ModuleTitle}}Repository) Create(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error) {
+	created, err := r.{{.ModuleTitle}}Store.Create(ctx, {{$var}})
+	if err != nil {
+		return nil, err
+	}
+	// An earlier lookup may have cached the ID as missing
+	r.evict(ctx, created.ID)
+	return created, nil
+}
+
+func (r *Cached{{.ModuleTitle}}Repository) Update(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error) {
+	updated, err := r.{{.ModuleTitle}}Store.Update(ctx, {{$var}})
+	if err != nil {
+		return nil, err
+	}
+	r.evict(ctx, {{$var}}.ID)
+	return updated, nil
+}
+
+func (r *Cached{{.ModuleTitle}}Repository) Delete(ctx context.Context, id uint) error {
+	if err := r.{{.ModuleTitle}}Store.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.evict(ctx, id)
+	return nil
+}
+
+func (r *Cached{{.ModuleTitle}}Repository) evict(ctx context.Context, id uint) {
+	if err := r.cache.Delete(ctx, {{$var}}CacheKey(id)); err != nil {
+		log.Printf("Warning: failed to evict {{.ModuleName}} %d from the cache: %v", id, err)
+	}
+}`,
+
+	"cached_repository_test.go.tmpl": `{{- $var := camel .ModuleName -}}
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"{{.ProjectName}}/internal/cache"
+	"{{.ProjectName}}/internal/models"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fake{{.ModuleTitle}}Store keeps records in a map and counts lookups.
+type fake{{.ModuleTitle}}Store struct {
+	records map[uint]*models.{{.ModuleTitle}}
+	lookups int
+}
+
+func (s *fake{{.ModuleTitle}}Store) FindAll(ctx context.Context) ([]*models.{{.ModuleTitle}}, error) {
+	var all []*models.{{.ModuleTitle}}
+	for _, record := range s.records {
+		all = append(all, record)
+	}
+	return all, nil
+}
+
+func (s *fake{{.ModuleTitle}}Store) FindByID(ctx context.Context, id uint) (*models.{{.ModuleTitle}}, error) {
+	s.lookups++
+	record, ok := s.records[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (s *fake{{.ModuleTitle}}Store) Create(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error) {
+	{{$var}}.ID = uint(len(s.records) + 1)
+	s.records[{{$var}}.ID] = {{$var}}
+	return {{$var}}, nil
+}
+
+func (s *fake{{.ModuleTitle}}Store) Update(ctx context.Context, {{$var}} *models.{{.ModuleTitle}}) (*models.{{.ModuleTitle}}, error) {
+	s.records[{{$var}}.ID] = {{$var}}
+	return {{$var}}, nil
+}
+
+func (s *fake{{.ModuleTitle}}Store) Delete(ctx context.Context, id uint) error {
+	delete(s.records, id)
+	return nil
+}
+
+func newCached{{.ModuleTitle}}Repository(t *testing.T) (*Cached{{.ModuleTitle}}Repository, *fake{{.ModuleTitle}}Store) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := &fake{{.ModuleTitle}}Store{records: map[uint]*models.{{.ModuleTitle}}{}}
+	c := cache.NewWithStore(cache.NewRedisStore(client, "test:"), time.Minute)
+	return NewCached{{.ModuleTitle}}Repository(store, c), store
+}
+
+func TestCached{{.ModuleTitle}}Repository_FindByIDHitsStoreOnce(t *testing.T) {
+	repo, store := newCached{{.ModuleTitle}}Repository(t)
+	ctx := context.Background()
+	created, err := repo.Create(ctx, &models.{{.ModuleTitle}}{})
+	require.NoError(t, err)
+
+	for range 3 {
+		found, err := repo.FindByID(ctx, created.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, created.ID, found.ID)
+	}
+
+	assert.Equal(t, 1, store.lookups)
+}
+
+func TestCached{{.ModuleTitle}}Repository_CreateEvictsMissingEntry(t *testing.T) {
+	repo, _ := newCached{{.ModuleTitle}}Repository(t)
+	ctx := context.Background()
+
+	missing, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+	require.Nil(t, missing)
+
+	created, err := repo.Create(ctx, &models.{{.ModuleTitle}}{})
+	require.NoError(t, err)
+
+	found, err := repo.FindByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, found)
+}
+
+func TestCached{{.ModuleTitle}}Repository_UpdateEvicts(t *testing.T) {
+	repo, store := newCached{{.ModuleTitle}}Repository(t)
+	ctx := context.Background()
+	created, err := repo.Create(ctx, &models.{{.ModuleTitle}}{})
+	require.NoError(t, err)
+	_, err = repo.FindByID(ctx, created.ID)
+	require.NoError(t, err)
+
+	changed := *created
+	changed.UpdatedAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = repo.Update(ctx, &changed)
+	require.NoError(t, err)
+
+	found, err := repo.FindByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, changed.UpdatedAt.Equal(found.UpdatedAt))
+	assert.Equal(t, 2, store.lookups)
+}
+
+func TestCached{{.ModuleTitle}}Repository_DeleteEvicts(t *testing.T) {
+	repo, _ := newCached{{.ModuleTitle}}Repository(t)
+	ctx := context.Background()
+	created, err := repo.Create(ctx, &models.{{.ModuleTitle}}{})
+	require.NoError(t, err)
+	_, err = repo.FindByID(ctx, created.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(ctx, created.ID))
+
+	found, err := repo.FindByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}`,
+
+	"handler_test.go.tmpl": `{{- $path := kebab .ModuleName | pluralize -}}
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setup{{.ModuleTitle}}Router() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	// Invalid requests are rejected before the service is used
+	handler := New{{.ModuleTitle}}Handler(nil)
+
+	router := gin.New()
+	router.GET("/{{$path}}/:id", handler.Get{{.ModuleTitle}})
+	router.POST("/{{$path}}", handler.Create{{.ModuleTitle}})
+	router.PUT("/{{$path}}/:id", handler.Update{{.ModuleTitle}})
+	router.DELETE("/{{$path}}/:id", handler.Delete{{.ModuleTitle}})
+	return router
+}
+
+func Test{{.ModuleTitle}}Handler_InvalidID(t *testing.T) {
+	router := setup{{.ModuleTitle}}Router()
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		req, _ := http.NewRequest(method, "/{{$path}}/abc", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, method)
+	}
+}
+
+func Test{{.ModuleTitle}}Handler_CreateInvalidBody(t *testing.T) {
+	router := setup{{.ModuleTitle}}Router()
+
+	req, _ := http.NewRequest(http.MethodPost, "/{{$path}}", strings.NewReader("{invalid"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}`,
+}
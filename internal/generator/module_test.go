@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []Field
+		err  string
+	}{
+		{spec: "", want: nil},
+		{
+			spec: "title:string:required, Price:FLOAT ,sku:string:unique,status:string:default=draft",
+			want: []Field{
+				{Name: "title", Type: "string", Required: true},
+				{Name: "price", Type: "float"},
+				{Name: "sku", Type: "string", Unique: true},
+				{Name: "status", Type: "string", Default: "draft"},
+			},
+		},
+		{spec: "paidAt:time", want: []Field{{Name: "paid_at", Type: "time"}}},
+		{spec: "note:text:default=", want: []Field{{Name: "note", Type: "text"}}},
+		{spec: "title", err: "expected name:type"},
+		{spec: ":string", err: "invalid field name"},
+		{spec: "title:blob", err: `unknown type "blob"`},
+		{spec: "title:string:indexed", err: `unknown modifier "indexed"`},
+		{spec: "title:string,Title:text", err: `duplicate or reserved field "title"`},
+		{spec: "created_at:time", err: `duplicate or reserved field "created_at"`},
+		{spec: `status:string:default=x;not null`, err: "cannot contain"},
+		{spec: `status:string:default=a"b`, err: "cannot contain"},
+		{spec: "status:string:default=a`b", err: "cannot contain"},
+	}
+	for _, tt := range tests {
+		got, err := ParseFields(tt.spec)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseFields(%q) error = %v, want %q", tt.spec, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseFields(%q): %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFields(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateModuleRefusesToOverwrite(t *testing.T) {
+	work := sandbox(t)
+	if err := GenerateProject("myapp"); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(work, "myapp"))
+
+	if err := GenerateModule("product", ModuleOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	model := filepath.Join("internal", "models", "product.go")
+	edited := readFile(t, model) + "\n// hand-written change\n"
+	if err := os.WriteFile(model, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := GenerateModule("product", ModuleOptions{})
+	if err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Fatalf("GenerateModule over an existing module: %v, want a --force error", err)
+	}
+	if readFile(t, model) != edited {
+		t.Fatal("the refused generation still rewrote the model")
+	}
+
+	if err := GenerateModule("product", ModuleOptions{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, model) == edited {
+		t.Error("--force did not regenerate the model")
+	}
+}
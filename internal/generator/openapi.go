@@ -0,0 +1,573 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adipras/lupettogo/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+type OpenAPIOptions struct {
+	Dir      string
+	Output   string
+	BasePath string
+	Version  string
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                           `yaml:"openapi"`
+	Info       openAPIInfo                      `yaml:"info"`
+	Servers    []openAPIServer                  `yaml:"servers,omitempty"`
+	Paths      map[string]map[string]*openAPIOp `yaml:"paths"`
+	Components openAPIComponents                `yaml:"components,omitempty"`
+}
+
+type openAPIInfo struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+type openAPIServer struct {
+	URL string `yaml:"url"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `yaml:"schemas,omitempty"`
+}
+
+type openAPIOp struct {
+	Tags        []string                    `yaml:"tags,omitempty"`
+	Summary     string                      `yaml:"summary,omitempty"`
+	Description string                      `yaml:"description,omitempty"`
+	OperationID string                      `yaml:"operationId,omitempty"`
+	Parameters  []*openAPIParameter         `yaml:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `yaml:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `yaml:"name"`
+	In          string         `yaml:"in"`
+	Description string         `yaml:"description,omitempty"`
+	Required    bool           `yaml:"required,omitempty"`
+	Schema      *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Description string                       `yaml:"description,omitempty"`
+	Required    bool                         `yaml:"required,omitempty"`
+	Content     map[string]*openAPIMediaType `yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `yaml:"description"`
+	Content     map[string]*openAPIMediaType `yaml:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `yaml:"$ref,omitempty"`
+	Type                 string                    `yaml:"type,omitempty"`
+	Format               string                    `yaml:"format,omitempty"`
+	Items                *openAPISchema            `yaml:"items,omitempty"`
+	Properties           map[string]*openAPISchema `yaml:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `yaml:"additionalProperties,omitempty"`
+	Required             []string                  `yaml:"required,omitempty"`
+	Nullable             bool                      `yaml:"nullable,omitempty"`
+}
+
+// GenerateOpenAPI builds an OpenAPI 3 document from the swag-style
+// annotations on the project's handlers and the structs in its models package.
+func GenerateOpenAPI(opts OpenAPIOptions) error {
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+	if opts.Output == "" {
+		opts.Output = "openapi.yaml"
+	}
+	if opts.BasePath == "" {
+		opts.BasePath = "/api/v1"
+	}
+	if opts.Version == "" {
+		opts.Version = "1.0.0"
+	}
+
+	manifest, err := loadOrDetectManifest(opts.Dir)
+	if err != nil {
+		return err
+	}
+
+	schemas, err := parseModelSchemas(filepath.Join(opts.Dir, "internal", "models"))
+	if err != nil {
+		return fmt.Errorf("failed to parse models: %w", err)
+	}
+
+	paths, err := parseHandlerOperations(filepath.Join(opts.Dir, "internal", "handlers"))
+	if err != nil {
+		return fmt.Errorf("failed to parse handlers: %w", err)
+	}
+
+	doc := openAPIDocument{
+		OpenAPI:    "3.0.3",
+		Info:       openAPIInfo{Title: manifest.Module, Version: opts.Version},
+		Servers:    []openAPIServer{{URL: opts.BasePath}},
+		Paths:      paths,
+		Components: openAPIComponents{Schemas: schemas},
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+
+	target := opts.Output
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(opts.Dir, target)
+	}
+	_, statErr := os.Stat(target)
+	if err := os.WriteFile(target, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if statErr == nil {
+		output.Updated(target)
+	} else {
+		output.Created(target)
+	}
+
+	output.Printf("📘 OpenAPI spec written to %s (%d paths, %d schemas)\n", target, len(paths), len(schemas))
+	return nil
+}
+
+func parseGoFiles(dir string) (*token.FileSet, []*ast.File, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range matches {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, file)
+	}
+	return fset, files, nil
+}
+
+func parseHandlerOperations(dir string) (map[string]map[string]*openAPIOp, error) {
+	fset, files, err := parseGoFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]map[string]*openAPIOp{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+
+			path, method, op, err := parseOperation(fn)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fset.Position(fn.Pos()), err)
+			}
+			if op == nil {
+				continue
+			}
+
+			if paths[path] == nil {
+				paths[path] = map[string]*openAPIOp{}
+			}
+			paths[path][method] = op
+		}
+	}
+	return paths, nil
+}
+
+// parseOperation reads the annotations of a single handler. It returns a nil
+// operation for functions that carry no @Router annotation.
+func parseOperation(fn *ast.FuncDecl) (string, string, *openAPIOp, error) {
+	op := &openAPIOp{
+		OperationID: fn.Name.Name,
+		Responses:   map[string]*openAPIResponse{},
+	}
+	var path, method string
+	var accept, produce []string
+
+	for _, comment := range fn.Doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		attr, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(attr) {
+		case "@summary":
+			op.Summary = value
+		case "@description":
+			if op.Description != "" {
+				op.Description += "\n"
+			}
+			op.Description += value
+		case "@tags":
+			for _, tag := range strings.Split(value, ",") {
+				op.Tags = append(op.Tags, strings.TrimSpace(tag))
+			}
+		case "@accept":
+			accept = append(accept, mimeTypes(value)...)
+		case "@produce":
+			produce = append(produce, mimeTypes(value)...)
+		case "@param":
+			if err := parseParam(op, value); err != nil {
+				return "", "", nil, err
+			}
+		case "@success", "@failure":
+			if err := parseResponse(op, value); err != nil {
+				return "", "", nil, err
+			}
+		case "@router":
+			fields := strings.Fields(value)
+			if len(fields) != 2 {
+				return "", "", nil, fmt.Errorf("invalid @Router annotation %q", value)
+			}
+			path = fields[0]
+			method = strings.ToLower(strings.Trim(fields[1], "[]"))
+		}
+	}
+
+	if path == "" {
+		return "", "", nil, nil
+	}
+
+	if len(accept) == 0 {
+		accept = []string{"application/json"}
+	}
+	if len(produce) == 0 {
+		produce = []string{"application/json"}
+	}
+	if op.RequestBody != nil {
+		schema := op.RequestBody.Content[""].Schema
+		op.RequestBody.Content = contentFor(accept, schema)
+	}
+	for _, resp := range op.Responses {
+		if schema, ok := resp.Content[""]; ok {
+			resp.Content = contentFor(produce, schema.Schema)
+		}
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &openAPIResponse{Description: "Default response"}
+	}
+
+	return path, method, op, nil
+}
+
+// parseParam handles "name in type required "description"".
+func parseParam(op *openAPIOp, value string) error {
+	fields := splitAnnotation(value)
+	if len(fields) < 4 {
+		return fmt.Errorf("invalid @Param annotation %q", value)
+	}
+
+	name, in, typ := fields[0], fields[1], fields[2]
+	required, _ := strconv.ParseBool(fields[3])
+	var description string
+	if len(fields) > 4 {
+		description = fields[4]
+	}
+
+	if in == "body" {
+		op.RequestBody = &openAPIRequestBody{
+			Description: description,
+			Required:    required,
+			Content:     map[string]*openAPIMediaType{"": {Schema: schemaForType(typ)}},
+		}
+		return nil
+	}
+
+	if in == "path" {
+		required = true
+	}
+	op.Parameters = append(op.Parameters, &openAPIParameter{
+		Name:        name,
+		In:          in,
+		Description: description,
+		Required:    required,
+		Schema:      schemaForType(typ),
+	})
+	return nil
+}
+
+// parseResponse handles "code {kind} type "description"", where everything
+// after the status code is optional.
+func parseResponse(op *openAPIOp, value string) error {
+	fields := splitAnnotation(value)
+	if len(fields) == 0 {
+		return fmt.Errorf("invalid response annotation %q", value)
+	}
+
+	code, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return fmt.Errorf("invalid status code in %q", value)
+	}
+
+	resp := &openAPIResponse{Description: http.StatusText(code)}
+	if len(fields) >= 3 {
+		schema := schemaForType(fields[2])
+		if fields[1] == "{array}" {
+			schema = &openAPISchema{Type: "array", Items: schema}
+		}
+		resp.Content = map[string]*openAPIMediaType{"": {Schema: schema}}
+	}
+	if len(fields) >= 4 {
+		resp.Description = fields[3]
+	}
+
+	op.Responses[strconv.Itoa(code)] = resp
+	return nil
+}
+
+// splitAnnotation splits on whitespace while keeping quoted strings intact.
+func splitAnnotation(value string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuotes := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			fields = append(fields, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range value {
+		switch {
+		case r == '"':
+			if inQuotes {
+				fields = append(fields, current.String())
+				current.Reset()
+			} else {
+				flush()
+			}
+			inQuotes = !inQuotes
+		case (r == ' ' || r == '\t') && !inQuotes:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return fields
+}
+
+func mimeTypes(value string) []string {
+	aliases := map[string]string{
+		"json":                  "application/json",
+		"xml":                   "application/xml",
+		"plain":                 "text/plain",
+		"html":                  "text/html",
+		"mpfd":                  "multipart/form-data",
+		"x-www-form-urlencoded": "application/x-www-form-urlencoded",
+	}
+
+	var types []string
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if alias, ok := aliases[v]; ok {
+			v = alias
+		}
+		if v != "" {
+			types = append(types, v)
+		}
+	}
+	return types
+}
+
+func contentFor(mimes []string, schema *openAPISchema) map[string]*openAPIMediaType {
+	content := map[string]*openAPIMediaType{}
+	for _, mime := range mimes {
+		content[mime] = &openAPIMediaType{Schema: schema}
+	}
+	return content
+}
+
+// schemaForType maps an annotation type such as "int", "models.User" or
+// "map[string]string" to a schema.
+func schemaForType(typ string) *openAPISchema {
+	if strings.HasPrefix(typ, "[]") {
+		return &openAPISchema{Type: "array", Items: schemaForType(typ[2:])}
+	}
+	if strings.HasPrefix(typ, "map[") {
+		if end := strings.Index(typ, "]"); end > 0 {
+			return &openAPISchema{Type: "object", AdditionalProperties: schemaForType(typ[end+1:])}
+		}
+	}
+	if name, ok := strings.CutPrefix(typ, "models."); ok {
+		return &openAPISchema{Ref: "#/components/schemas/" + name}
+	}
+	return primitiveSchema(typ)
+}
+
+func primitiveSchema(typ string) *openAPISchema {
+	switch typ {
+	case "string":
+		return &openAPISchema{Type: "string"}
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32":
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case "int64", "uint64":
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &openAPISchema{Type: "number", Format: "float"}
+	case "float64", "number":
+		return &openAPISchema{Type: "number", Format: "double"}
+	case "bool", "boolean":
+		return &openAPISchema{Type: "boolean"}
+	case "time.Time":
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case "object":
+		return &openAPISchema{Type: "object"}
+	}
+	// interface{}, any and unknown types accept any value
+	return &openAPISchema{}
+}
+
+func parseModelSchemas(dir string) (map[string]*openAPISchema, error) {
+	_, files, err := parseGoFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	structs := map[string]*ast.StructType{}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			if st, ok := spec.Type.(*ast.StructType); ok && spec.Name.IsExported() {
+				structs[spec.Name.Name] = st
+			}
+			return false
+		})
+	}
+
+	schemas := map[string]*openAPISchema{}
+	for name, st := range structs {
+		schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+		addStructFields(schema, st, structs)
+		sort.Strings(schema.Required)
+		schemas[name] = schema
+	}
+	return schemas, nil
+}
+
+func addStructFields(schema *openAPISchema, st *ast.StructType, structs map[string]*ast.StructType) {
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		}
+
+		jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		// Embedded structs are flattened the same way encoding/json does
+		if len(field.Names) == 0 {
+			typ := exprString(field.Type)
+			if embedded, ok := structs[typ]; ok && jsonName == "" {
+				addStructFields(schema, embedded, structs)
+			} else if typ == "gorm.Model" && jsonName == "" {
+				schema.Properties["ID"] = primitiveSchema("uint")
+				schema.Properties["CreatedAt"] = primitiveSchema("time.Time")
+				schema.Properties["UpdatedAt"] = primitiveSchema("time.Time")
+				schema.Properties["DeletedAt"] = deletedAtSchema()
+			}
+			continue
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			propName := jsonName
+			if propName == "" {
+				propName = name.Name
+			}
+			schema.Properties[propName] = fieldSchema(field.Type, structs)
+
+			if strings.Contains(tag.Get("binding"), "required") || strings.Contains(tag.Get("validate"), "required") {
+				schema.Required = append(schema.Required, propName)
+			}
+		}
+	}
+}
+
+func fieldSchema(expr ast.Expr, structs map[string]*ast.StructType) *openAPISchema {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return fieldSchema(t.X, structs)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: fieldSchema(t.Elt, structs)}
+	case *ast.MapType:
+		return &openAPISchema{Type: "object", AdditionalProperties: fieldSchema(t.Value, structs)}
+	case *ast.Ident:
+		if _, ok := structs[t.Name]; ok {
+			return &openAPISchema{Ref: "#/components/schemas/" + t.Name}
+		}
+	case *ast.SelectorExpr:
+		switch exprString(t) {
+		case "gorm.DeletedAt", "sql.NullTime":
+			return deletedAtSchema()
+		case "datatypes.JSON", "json.RawMessage":
+			return &openAPISchema{}
+		}
+	}
+	return primitiveSchema(exprString(expr))
+}
+
+// deletedAtSchema describes gorm.DeletedAt, which encodes as null until the
+// record is soft deleted.
+func deletedAtSchema() *openAPISchema {
+	return &openAPISchema{Type: "string", Format: "date-time", Nullable: true}
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return exprString(t.X)
+	}
+	return ""
+}
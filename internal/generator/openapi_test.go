@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func parseFuncDecl(t *testing.T, src string) *ast.FuncDecl {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "handler.go", "package handlers\n\n"+src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Decls[0].(*ast.FuncDecl)
+}
+
+func TestSplitAnnotation(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{`id path int true "Product ID"`, []string{"id", "path", "int", "true", "Product ID"}},
+		{`200  {object}	models.Product`, []string{"200", "{object}", "models.Product"}},
+		{`q query string false ""`, []string{"q", "query", "string", "false", ""}},
+		{`404 {object} map[string]string "Not   found"`, []string{"404", "{object}", "map[string]string", "Not   found"}},
+		{``, nil},
+	}
+	for _, tt := range tests {
+		if got := splitAnnotation(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitAnnotation(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseOperation(t *testing.T) {
+	fn := parseFuncDecl(t, `
+// UpdateProduct godoc
+// @Summary Update a product
+// @Description Replaces the product.
+// @Description Unknown fields are ignored.
+// @Tags products, admin
+// @Accept json
+// @Produce json,xml
+// @Param id path int false "Product ID"
+// @Param dry_run query bool false "Validate only"
+// @Param product body models.Product true "Product"
+// @Success 200 {object} models.Product
+// @Success 204
+// @Failure 404 {object} map[string]string "Product not found"
+// @Router /products/{id} [PUT]
+func UpdateProduct() {}
+`)
+
+	path, method, op, err := parseOperation(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/products/{id}" || method != "put" {
+		t.Fatalf("route = %s %s, want put /products/{id}", method, path)
+	}
+	if op.OperationID != "UpdateProduct" || op.Summary != "Update a product" {
+		t.Errorf("operationId/summary = %q/%q", op.OperationID, op.Summary)
+	}
+	if op.Description != "Replaces the product.\nUnknown fields are ignored." {
+		t.Errorf("description = %q", op.Description)
+	}
+	if !reflect.DeepEqual(op.Tags, []string{"products", "admin"}) {
+		t.Errorf("tags = %q", op.Tags)
+	}
+
+	if len(op.Parameters) != 2 {
+		t.Fatalf("got %d parameters, want 2 (the body is a request body)", len(op.Parameters))
+	}
+	id := op.Parameters[0]
+	if id.Name != "id" || id.In != "path" || !id.Required || id.Schema.Type != "integer" {
+		t.Errorf("path parameter = %+v, path parameters are always required", id)
+	}
+	if dry := op.Parameters[1]; dry.Required || dry.Schema.Type != "boolean" {
+		t.Errorf("query parameter = %+v", dry)
+	}
+
+	body := op.RequestBody
+	if body == nil || !body.Required || len(body.Content) != 1 || body.Content["application/json"].Schema.Ref != "#/components/schemas/Product" {
+		t.Errorf("request body = %+v", body)
+	}
+
+	ok := op.Responses["200"]
+	if ok.Description != "OK" || len(ok.Content) != 2 || ok.Content["application/xml"].Schema.Ref != "#/components/schemas/Product" {
+		t.Errorf("200 response = %+v", ok)
+	}
+	if noContent := op.Responses["204"]; noContent.Description != "No Content" || noContent.Content != nil {
+		t.Errorf("204 response = %+v", noContent)
+	}
+	notFound := op.Responses["404"]
+	if notFound.Description != "Product not found" || notFound.Content["application/json"].Schema.AdditionalProperties.Type != "string" {
+		t.Errorf("404 response = %+v", notFound)
+	}
+}
+
+func TestParseOperationWithoutRouter(t *testing.T) {
+	fn := parseFuncDecl(t, `
+// helper is not a route
+// @Summary Not exported as an operation
+func helper() {}
+`)
+	_, _, op, err := parseOperation(fn)
+	if err != nil || op != nil {
+		t.Errorf("parseOperation() = %v, %v; want no operation", op, err)
+	}
+}
+
+func TestParseOperationErrors(t *testing.T) {
+	tests := map[string]string{
+		"router without method": `// @Router /products`,
+		"short param":           `// @Param id path int`,
+		"bad status code":       `// @Success ok {object} models.Product`,
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fn := parseFuncDecl(t, doc+"\n// @Router /x [get]\nfunc F() {}\n")
+			if _, _, _, err := parseOperation(fn); err == nil {
+				t.Errorf("parseOperation accepted %q", doc)
+			}
+		})
+	}
+}
+
+func TestSchemaForType(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want *openAPISchema
+	}{
+		{"string", &openAPISchema{Type: "string"}},
+		{"int", &openAPISchema{Type: "integer", Format: "int32"}},
+		{"uint64", &openAPISchema{Type: "integer", Format: "int64"}},
+		{"float64", &openAPISchema{Type: "number", Format: "double"}},
+		{"time.Time", &openAPISchema{Type: "string", Format: "date-time"}},
+		{"interface{}", &openAPISchema{}},
+		{"models.User", &openAPISchema{Ref: "#/components/schemas/User"}},
+		{"[]models.User", &openAPISchema{Type: "array", Items: &openAPISchema{Ref: "#/components/schemas/User"}}},
+		{"map[string]int64", &openAPISchema{Type: "object", AdditionalProperties: &openAPISchema{Type: "integer", Format: "int64"}}},
+	}
+	for _, tt := range tests {
+		if got := schemaForType(tt.typ); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("schemaForType(%q) = %+v, want %+v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestParseModelSchemas(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"models.go": "package models\n\n" +
+			"import (\n\t\"time\"\n\n\t\"gorm.io/gorm\"\n)\n\n" +
+			"type Base struct {\n\tNote string `json:\"note\"`\n}\n\n" +
+			"type Order struct {\n" +
+			"\tgorm.Model\n" +
+			"\tBase\n" +
+			"\tCustomer  string            `json:\"customer\" binding:\"required\"`\n" +
+			"\tItems     []Item            `json:\"items\"`\n" +
+			"\tShippedAt *time.Time        `json:\"shipped_at\"`\n" +
+			"\tArchived  gorm.DeletedAt    `json:\"archived_at\"`\n" +
+			"\tLabels    map[string]string `json:\"labels\"`\n" +
+			"\tPayload   []byte            `json:\"payload\" validate:\"required\"`\n" +
+			"\tSecret    string            `json:\"-\"`\n" +
+			"\tinternal  string\n" +
+			"}\n\n" +
+			"type Item struct {\n\tSKU string\n}\n\n" +
+			"type hidden struct{}\n",
+		"models_test.go": "package models\n\ntype Fixture struct{}\n",
+	})
+
+	schemas, err := parseModelSchemas(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := schemas["hidden"]; ok {
+		t.Error("unexported structs must not become schemas")
+	}
+	if _, ok := schemas["Fixture"]; ok {
+		t.Error("test files must be ignored")
+	}
+
+	order := schemas["Order"]
+	if order == nil {
+		t.Fatalf("no Order schema in %v", schemas)
+	}
+	want := map[string]*openAPISchema{
+		"ID":          {Type: "integer", Format: "int32"},
+		"CreatedAt":   {Type: "string", Format: "date-time"},
+		"UpdatedAt":   {Type: "string", Format: "date-time"},
+		"DeletedAt":   {Type: "string", Format: "date-time", Nullable: true},
+		"note":        {Type: "string"},
+		"customer":    {Type: "string"},
+		"items":       {Type: "array", Items: &openAPISchema{Ref: "#/components/schemas/Item"}},
+		"shipped_at":  {Type: "string", Format: "date-time"},
+		"archived_at": {Type: "string", Format: "date-time", Nullable: true},
+		"labels":      {Type: "object", AdditionalProperties: &openAPISchema{Type: "string"}},
+		"payload":     {Type: "string", Format: "byte"},
+	}
+	if !reflect.DeepEqual(order.Properties, want) {
+		t.Errorf("Order properties =\n%v\nwant\n%v", order.Properties, want)
+	}
+	if !reflect.DeepEqual(order.Required, []string{"customer", "payload"}) {
+		t.Errorf("Order required = %q", order.Required)
+	}
+	if _, ok := schemas["Item"].Properties["SKU"]; !ok {
+		t.Error("fields without a json tag keep their Go name")
+	}
+}
+
+func TestGenerateOpenAPIDefaultProject(t *testing.T) {
+	work := sandbox(t)
+	if err := GenerateProject("myapp"); err != nil {
+		t.Fatal(err)
+	}
+
+	// init writes the spec, generating it again must give the same document
+	spec := filepath.Join(work, "myapp", "openapi.yaml")
+	generated := readFile(t, spec)
+	if err := GenerateOpenAPI(OpenAPIOptions{Dir: filepath.Join(work, "myapp")}); err != nil {
+		t.Fatal(err)
+	}
+	regenerated := readFile(t, spec)
+	if generated != regenerated {
+		t.Error("regenerating the spec changed it")
+	}
+
+	assertGolden(t, "openapi_default.yaml", []byte(regenerated))
+}
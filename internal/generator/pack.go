@@ -0,0 +1,503 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adipras/lupettogo/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+// PackManifestName is the manifest every template pack carries at its root.
+const PackManifestName = "lupettogo-pack.yaml"
+
+// packLockName records what was fetched into a cached pack.
+const packLockName = ".lupettogo-pack.lock"
+
+// TemplatePack is a set of templates shared outside the CLI, fetched from a
+// git repository or read from a local directory. Its files/ directory is
+// layered on top of the embedded templates.
+type TemplatePack struct {
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description"`
+	Options     []PackOption `yaml:"options"`
+	Hooks       []PackHook   `yaml:"hooks"`
+	Checks      []PackCheck  `yaml:"checks"`
+
+	Source   string `yaml:"-"`
+	Dir      string `yaml:"-"`
+	Checksum string `yaml:"-"`
+
+	// local is set for packs read from a directory rather than fetched
+	local bool
+}
+
+// PackOption is a value the pack asks for when a project is generated.
+// Templates read it as {{.Options.name}}.
+type PackOption struct {
+	Name    string   `yaml:"name"`
+	Prompt  string   `yaml:"prompt"`
+	Type    string   `yaml:"type"`
+	Default string   `yaml:"default"`
+	Choices []string `yaml:"choices"`
+}
+
+// PackCheck is a tool 'lupettogo doctor' checks for in projects generated
+// from the pack.
+type PackCheck struct {
+	Name      string   `yaml:"name"`
+	Command   string   `yaml:"command"`
+	Args      []string `yaml:"args"`
+	Version   string   `yaml:"version"`
+	Severity  string   `yaml:"severity"`
+	Platforms []string `yaml:"platforms"`
+	Install   string   `yaml:"install"`
+}
+
+type packLock struct {
+	Source    string    `yaml:"source"`
+	Ref       string    `yaml:"ref"`
+	Commit    string    `yaml:"commit"`
+	Checksum  string    `yaml:"checksum"`
+	FetchedAt time.Time `yaml:"fetched_at"`
+}
+
+// packSource is a parsed --template value.
+type packSource struct {
+	raw  string
+	url  string
+	ref  string
+	path string
+}
+
+// FilesDir returns the directory holding the pack's templates.
+func (p *TemplatePack) FilesDir() string {
+	return filepath.Join(p.Dir, "files")
+}
+
+// PacksCacheDir returns where fetched template packs are cached.
+func PacksCacheDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "lupettogo", "packs"), nil
+}
+
+// parsePackSource accepts git+<url>[@ref] for git repositories and plain
+// filesystem paths for local packs. Sources are read back from committed
+// manifests, so URLs and refs that git would take for options are refused.
+func parsePackSource(source string) (packSource, error) {
+	if url, ok := strings.CutPrefix(source, "git+"); ok {
+		ref := ""
+		// The ref follows the last @ of the path, not the one in user@host
+		if at := strings.LastIndex(url, "@"); at > strings.LastIndex(url, "/") {
+			url, ref = url[:at], url[at+1:]
+		}
+		if url == "" || strings.HasPrefix(url, "-") || strings.HasPrefix(ref, "-") {
+			return packSource{}, fmt.Errorf("invalid template source %q", source)
+		}
+		return packSource{raw: source, url: url, ref: ref}, nil
+	}
+
+	path := localPackPath(source)
+	if !isDir(path) {
+		return packSource{}, fmt.Errorf("template source %q is neither a git+ URL nor a directory", source)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return packSource{}, err
+	}
+	return packSource{raw: source, path: abs}, nil
+}
+
+func localPackPath(source string) string {
+	path, _ := strings.CutPrefix(source, "file://")
+	return filepath.FromSlash(path)
+}
+
+// LoadTemplatePack resolves a template source, fetching git sources into the
+// cache unless a verified copy is already there or refresh is set. Relative
+// local sources are resolved against the working directory.
+func LoadTemplatePack(source string, refresh bool) (*TemplatePack, error) {
+	src, err := parsePackSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := src.path
+	if dir == "" {
+		dir, err = cachedPack(src, refresh)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pack, err := readTemplatePack(dir)
+	if err != nil {
+		return nil, err
+	}
+	pack.Source = source
+	if src.path != "" {
+		pack.Source = src.path
+		pack.local = true
+	}
+	return pack, nil
+}
+
+// LoadProjectPack loads the template pack recorded in the manifest of the
+// project in projectDir. Relative local sources are resolved against the
+// project rather than the working directory.
+func LoadProjectPack(projectDir string, template *ManifestTemplate) (*TemplatePack, error) {
+	source := template.Source
+	if !strings.HasPrefix(source, "git+") {
+		if path := localPackPath(source); !filepath.IsAbs(path) {
+			source = filepath.Join(projectDir, path)
+		}
+	}
+	return LoadTemplatePack(source, false)
+}
+
+// manifestSource is the source recorded in the manifest of a project
+// generated into projectDir. Local packs are recorded relative to the
+// project so the manifest works in every checkout.
+func (p *TemplatePack) manifestSource(projectDir string) string {
+	if !p.local {
+		return p.Source
+	}
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return p.Source
+	}
+	rel, err := filepath.Rel(abs, p.Source)
+	if err != nil {
+		return p.Source
+	}
+	return filepath.ToSlash(rel)
+}
+
+func readTemplatePack(dir string) (*TemplatePack, error) {
+	content, err := os.ReadFile(filepath.Join(dir, PackManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("not a template pack, %s is missing: %w", PackManifestName, err)
+	}
+
+	var pack TemplatePack
+	if err := yaml.Unmarshal(content, &pack); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", PackManifestName, err)
+	}
+	pack.Dir = dir
+
+	if !isDir(pack.FilesDir()) {
+		return nil, fmt.Errorf("template pack %s has no files/ directory", dir)
+	}
+	for _, option := range pack.Options {
+		if option.Name == "" {
+			return nil, fmt.Errorf("template pack %s declares an option without a name", dir)
+		}
+	}
+	for _, hook := range pack.Hooks {
+		if hook.Name == "" || hook.Run == "" {
+			return nil, fmt.Errorf("template pack %s declares a hook without a name or run command", dir)
+		}
+	}
+	for _, check := range pack.Checks {
+		if check.Command == "" {
+			return nil, fmt.Errorf("template pack %s declares a check without a command", dir)
+		}
+		if check.Severity != "" && check.Severity != "required" && check.Severity != "optional" {
+			return nil, fmt.Errorf("template pack %s check %q has severity %q (expected required or optional)", dir, check.Command, check.Severity)
+		}
+	}
+
+	pack.Checksum, err = packChecksum(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+// cachedPack returns the cache directory for src, fetching it when missing,
+// when refresh is set or when its contents no longer match the lock file.
+func cachedPack(src packSource, refresh bool) (string, error) {
+	cacheRoot, err := PacksCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate template cache: %w", err)
+	}
+
+	ref := src.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	dir := filepath.Join(cacheRoot, cacheKey(src.url), cacheKey(ref))
+
+	if !refresh {
+		verified, err := verifyCachedPack(dir)
+		if err != nil {
+			output.Warnf("Cached template pack failed verification, fetching again: %v", err)
+		}
+		if verified {
+			return dir, nil
+		}
+	}
+
+	output.Printf("📥 Fetching template pack %s\n", src.raw)
+	if err := fetchPack(src, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func verifyCachedPack(dir string) (bool, error) {
+	content, err := os.ReadFile(filepath.Join(dir, packLockName))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var lock packLock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return false, err
+	}
+
+	sum, err := packChecksum(dir)
+	if err != nil {
+		return false, err
+	}
+	if sum != lock.Checksum {
+		return false, fmt.Errorf("checksum mismatch in %s", dir)
+	}
+	return true, nil
+}
+
+// fetchPack clones the pack into a temporary directory and moves it into
+// place, so a failed fetch never leaves a half-written cache entry behind.
+func fetchPack(src packSource, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".fetch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	checkout := filepath.Join(tmp, "pack")
+	if err := cloneRef(src.url, src.ref, checkout); err != nil {
+		return err
+	}
+
+	commit, err := runGit(checkout, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(checkout, ".git")); err != nil {
+		return err
+	}
+
+	// Validate before caching
+	if _, err := readTemplatePack(checkout); err != nil {
+		return err
+	}
+	sum, err := packChecksum(checkout)
+	if err != nil {
+		return err
+	}
+
+	lock, err := yaml.Marshal(packLock{
+		Source:    src.raw,
+		Ref:       src.ref,
+		Commit:    commit,
+		Checksum:  sum,
+		FetchedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(checkout, packLockName), lock, 0644); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(checkout, dir)
+}
+
+func cloneRef(url, ref, dest string) error {
+	if ref == "" {
+		_, err := runGit("", "clone", "--depth", "1", "--", url, dest)
+		return err
+	}
+
+	// Branches and tags can be cloned shallowly; commits need a full clone
+	if _, err := runGit("", "clone", "--depth", "1", "--branch", ref, "--", url, dest); err == nil {
+		return nil
+	}
+	os.RemoveAll(dest)
+
+	if _, err := runGit("", "clone", "--", url, dest); err != nil {
+		return err
+	}
+	if _, err := runGit(dest, "checkout", "--quiet", ref); err != nil {
+		return fmt.Errorf("ref %q not found in %s: %w", ref, url, err)
+	}
+	return nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("git is required to fetch template packs")
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+var unsafeCacheChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func cacheKey(s string) string {
+	s = strings.TrimPrefix(s, "https://")
+	s = strings.TrimPrefix(s, "ssh://")
+	return strings.Trim(unsafeCacheChars.ReplaceAllString(s, "_"), "_.")
+}
+
+// packChecksum hashes every file of the pack except the lock file.
+func packChecksum(dir string) (string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Name() != packLockName {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	hash := sha256.New()
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		fmt.Fprintf(hash, "%s\x00%d\x00", filepath.ToSlash(rel), len(content))
+		hash.Write(content)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ResolvePackOptions combines explicitly set values with the pack's
+// defaults, prompting for the rest on stdin when interactive is set.
+func ResolvePackOptions(pack *TemplatePack, values map[string]string, interactive bool) (map[string]any, error) {
+	resolved := map[string]any{}
+	known := map[string]bool{}
+	reader := bufio.NewReader(os.Stdin)
+
+	for _, option := range pack.Options {
+		known[option.Name] = true
+
+		value, ok := values[option.Name]
+		if !ok && interactive {
+			var err error
+			if value, err = promptOption(reader, option); err != nil {
+				return nil, err
+			}
+			ok = value != ""
+		}
+		if !ok {
+			value = option.Default
+		}
+
+		typed, err := option.parse(value)
+		if err != nil {
+			return nil, err
+		}
+		resolved[option.Name] = typed
+	}
+
+	for name := range values {
+		if !known[name] {
+			return nil, fmt.Errorf("template pack %s has no option %q", pack.Name, name)
+		}
+	}
+	return resolved, nil
+}
+
+func promptOption(reader *bufio.Reader, option PackOption) (string, error) {
+	prompt := option.Prompt
+	if prompt == "" {
+		prompt = option.Name
+	}
+	if len(option.Choices) > 0 {
+		prompt += " (" + strings.Join(option.Choices, "/") + ")"
+	}
+	if option.Default != "" {
+		prompt += " [" + option.Default + "]"
+	}
+	output.Printf("❓ %s: ", prompt)
+
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", nil
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (o PackOption) parse(value string) (any, error) {
+	switch o.Type {
+	case "bool":
+		if value == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %s expects true or false, got %q", o.Name, value)
+		}
+		return b, nil
+	case "choice":
+		for _, choice := range o.Choices {
+			if value == choice {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("option %s must be one of %s, got %q", o.Name, strings.Join(o.Choices, ", "), value)
+	case "", "string":
+		return value, nil
+	}
+	return nil, fmt.Errorf("option %s has unknown type %q", o.Name, o.Type)
+}
@@ -0,0 +1,267 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testPackManifest = `name: acme-starter
+options:
+  - name: team
+    default: platform
+`
+
+// gitRemote creates a bare repository standing in for a remote and returns a
+// function committing files to it. The commit is tagged when tag is set.
+func gitRemote(t *testing.T) (string, func(files map[string]string, tag string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	bare := filepath.Join(root, "pack.git")
+	work := filepath.Join(root, "work")
+	mustGit(t, root, "init", "--quiet", "--bare", bare)
+	mustGit(t, root, "clone", "--quiet", bare, work)
+
+	commit := func(files map[string]string, tag string) {
+		t.Helper()
+		writeTree(t, work, files)
+		mustGit(t, work, "add", "-A")
+		mustGit(t, work, "commit", "--quiet", "-m", "update pack")
+		mustGit(t, work, "push", "--quiet", "origin", "HEAD")
+		if tag != "" {
+			mustGit(t, work, "tag", tag)
+			mustGit(t, work, "push", "--quiet", "origin", tag)
+		}
+	}
+	return bare, commit
+}
+
+func mustGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestLoadTemplatePackFromGit(t *testing.T) {
+	sandbox(t)
+	bare, commit := gitRemote(t)
+	commit(map[string]string{
+		PackManifestName:  testPackManifest,
+		"files/README.md": "v1\n",
+	}, "v1")
+	commit(map[string]string{"files/README.md": "v2\n"}, "")
+
+	source := "git+file://" + filepath.ToSlash(bare) + "@v1"
+	pack, err := LoadTemplatePack(source, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pack.Name != "acme-starter" || pack.Source != source {
+		t.Errorf("pack = %q from %q", pack.Name, pack.Source)
+	}
+	if got := readFile(t, filepath.Join(pack.FilesDir(), "README.md")); got != "v1\n" {
+		t.Errorf("README.md = %q, want the v1 tag's content", got)
+	}
+	if _, err := os.Stat(filepath.Join(pack.Dir, ".git")); !os.IsNotExist(err) {
+		t.Error("the cached pack still has its .git directory")
+	}
+
+	var lock packLock
+	if err := yaml.Unmarshal([]byte(readFile(t, filepath.Join(pack.Dir, packLockName))), &lock); err != nil {
+		t.Fatal(err)
+	}
+	if lock.Ref != "v1" || lock.Checksum != pack.Checksum || len(lock.Commit) != 40 {
+		t.Errorf("lock = %+v, want ref v1, the pack checksum and a commit", lock)
+	}
+
+	// The default branch is fetched without a ref
+	head, err := LoadTemplatePack("git+file://"+filepath.ToSlash(bare), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(head.FilesDir(), "README.md")); got != "v2\n" {
+		t.Errorf("README.md = %q, want the latest commit's content", got)
+	}
+}
+
+func TestLoadTemplatePackCache(t *testing.T) {
+	sandbox(t)
+	bare, commit := gitRemote(t)
+	commit(map[string]string{
+		PackManifestName:  testPackManifest,
+		"files/README.md": "v1\n",
+	}, "v1")
+	source := "git+file://" + filepath.ToSlash(bare) + "@v1"
+
+	first, err := LoadTemplatePack(source, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A verified cache is used without touching the remote
+	hidden := bare + ".hidden"
+	if err := os.Rename(bare, hidden); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := LoadTemplatePack(source, false)
+	if err != nil {
+		t.Fatalf("verified cache was not reused: %v", err)
+	}
+	if cached.Dir != first.Dir || cached.Checksum != first.Checksum {
+		t.Errorf("cached pack = %s (%s), want %s (%s)", cached.Dir, cached.Checksum, first.Dir, first.Checksum)
+	}
+
+	// A cache that no longer matches its lock file is fetched again
+	readme := filepath.Join(first.FilesDir(), "README.md")
+	if err := os.WriteFile(readme, []byte("tampered\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplatePack(source, false); err == nil {
+		t.Fatal("tampered cache was used while the remote is unreachable")
+	}
+	if err := os.Rename(hidden, bare); err != nil {
+		t.Fatal(err)
+	}
+	refetched, err := LoadTemplatePack(source, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, readme); got != "v1\n" || refetched.Checksum != first.Checksum {
+		t.Errorf("README.md = %q after refetching, want the original content", got)
+	}
+}
+
+func TestLoadTemplatePackErrors(t *testing.T) {
+	sandbox(t)
+	bare, commit := gitRemote(t)
+	commit(map[string]string{
+		PackManifestName:  "name: [unclosed\n",
+		"files/README.md": "v1\n",
+	}, "broken")
+	commit(map[string]string{PackManifestName: testPackManifest}, "v1")
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"unknown ref", "git+file://" + filepath.ToSlash(bare) + "@nope", `ref "nope" not found`},
+		{"invalid manifest", "git+file://" + filepath.ToSlash(bare) + "@broken", "invalid " + PackManifestName},
+		{"missing directory", "./no-such-pack", "neither a git+ URL nor a directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTemplatePack(tt.source, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadTemplatePack(%q) error = %v, want %q", tt.source, err, tt.want)
+			}
+		})
+	}
+
+	// Failed fetches leave nothing in the cache
+	cacheRoot, err := PacksCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := filepath.Glob(filepath.Join(cacheRoot, "*", "*"))
+	if len(entries) != 0 {
+		t.Errorf("failed fetches were cached: %v", entries)
+	}
+}
+
+func TestParsePackSource(t *testing.T) {
+	sandbox(t)
+	writeTree(t, ".", map[string]string{"local/" + PackManifestName: testPackManifest})
+
+	tests := []struct {
+		source  string
+		url     string
+		ref     string
+		local   bool
+		invalid bool
+	}{
+		{source: "git+https://example.com/acme/starter@v2", url: "https://example.com/acme/starter", ref: "v2"},
+		{source: "git+ssh://git@example.com/acme/starter", url: "ssh://git@example.com/acme/starter"},
+		{source: "git+git@example.com:acme/starter@main", url: "git@example.com:acme/starter", ref: "main"},
+		{source: "./local", local: true},
+		{source: "file://local", local: true},
+		{source: "git+", invalid: true},
+		{source: "git+--upload-pack=touch /tmp/pwned", invalid: true},
+		{source: "git+-c@v1", invalid: true},
+		{source: "git+https://example.com/acme/starter@--upload-pack=sh", invalid: true},
+		{source: "./missing", invalid: true},
+	}
+	for _, tt := range tests {
+		src, err := parsePackSource(tt.source)
+		if tt.invalid {
+			if err == nil {
+				t.Errorf("parsePackSource(%q) = %+v, want an error", tt.source, src)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parsePackSource(%q): %v", tt.source, err)
+			continue
+		}
+		if tt.local {
+			if !filepath.IsAbs(src.path) || filepath.Base(src.path) != "local" {
+				t.Errorf("parsePackSource(%q).path = %q, want an absolute path", tt.source, src.path)
+			}
+			continue
+		}
+		if src.url != tt.url || src.ref != tt.ref {
+			t.Errorf("parsePackSource(%q) = %q @ %q, want %q @ %q", tt.source, src.url, src.ref, tt.url, tt.ref)
+		}
+	}
+}
+
+func TestLocalPackRecordedRelativeToProject(t *testing.T) {
+	work := sandbox(t)
+	writeTree(t, work, map[string]string{
+		"packs/acme/" + PackManifestName: testPackManifest,
+		"packs/acme/files/NOTES.md":      "team {{.Options.team}}\n",
+	})
+
+	pack, err := LoadTemplatePack("packs/acme", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := ProjectConfig{Name: "myapp", DBDriver: "postgres", Pack: pack, Options: map[string]any{"team": "payments"}}
+	if err := GenerateProjectWithConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := LoadManifest("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Template.Source != "../packs/acme" {
+		t.Fatalf("recorded source = %q, want it relative to the project", manifest.Template.Source)
+	}
+
+	// The project resolves the pack from any working directory
+	t.Chdir(t.TempDir())
+	loaded, err := LoadProjectPack(filepath.Join(work, "myapp"), manifest.Template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Checksum != manifest.Template.Checksum {
+		t.Errorf("loaded %s, want the pack the project was generated from", loaded.Dir)
+	}
+}
@@ -1,31 +1,69 @@
 package generator
 
 import (
-	"embed"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
-	"text/template"
-)
 
-//go:embed templates/*
-var templatesFS embed.FS
+	"github.com/adipras/lupettogo/internal/output"
+)
 
 type ProjectData struct {
-	ProjectName string
-	DBDriver    string
-	WithAuth    bool
-	WithDocker  bool
-	WithTests   bool
+	ProjectName   string
+	DBDriver      string
+	WithAuth      bool
+	WithDocker    bool
+	WithTests     bool
+	WithRedis     bool
+	WithRateLimit bool
+	WithMetrics   bool
+	WithTracing   bool
+	WithJobs      bool
+	WithCache     bool
+	// WithKubernetes and WithHelm add deployment manifests, see GenerateDeploy
+	WithKubernetes bool
+	WithHelm       bool
+	CI             string
+	Options        map[string]any
+
+	// Filled from the dependency catalogue
+	GoVersion     string
+	BuilderImage  string
+	RuntimeImage  string
+	DatabaseImage string
+	RedisImage    string
+	LintVersion   string
+	Dependencies  []Dependency
+
+	// PlaceholderSecrets is shared with doctor, see IsPlaceholderSecret
+	PlaceholderSecrets []string
 }
 
 type ProjectConfig struct {
-	Name       string
-	DBDriver   string
-	WithAuth   bool
-	WithDocker bool
-	WithTests  bool
+	Name          string
+	DBDriver      string
+	WithAuth      bool
+	WithDocker    bool
+	WithTests     bool
+	WithRedis     bool
+	WithRateLimit bool
+	WithMetrics   bool
+	WithTracing   bool
+	WithJobs      bool
+	// CI is the CI provider to generate a pipeline for, if any.
+	CI string
+
+	// Pack is an optional template pack layered over the defaults, with
+	// Options holding the values for the options it declares.
+	Pack    *TemplatePack
+	Options map[string]any
+
+	// Hooks selects the steps run once the files are written.
+	Hooks HookOptions
 }
 
 func GenerateProject(projectName string) error {
@@ -42,94 +80,180 @@ func GenerateProject(projectName string) error {
 func GenerateProjectWithConfig(config ProjectConfig) error {
 	dest := config.Name
 
+	if err := ValidateDBDriver(config.DBDriver); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create project folder: %w", err)
 	}
 
 	data := ProjectData{
-		ProjectName: config.Name,
-		DBDriver:    config.DBDriver,
-		WithAuth:    config.WithAuth,
-		WithDocker:  config.WithDocker,
-		WithTests:   config.WithTests,
-	}
+		ProjectName:   config.Name,
+		DBDriver:      config.DBDriver,
+		WithAuth:      config.WithAuth,
+		WithDocker:    config.WithDocker,
+		WithTests:     config.WithTests,
+		WithRedis:     config.WithRedis,
+		WithRateLimit: config.WithRateLimit,
+		WithMetrics:   config.WithMetrics,
+		WithTracing:   config.WithTracing,
+		WithJobs:      config.WithJobs,
+		CI:            config.CI,
+		Options:       config.Options,
+	}.withCatalogue()
 
-	if err := processEmbeddedTemplates(dest, data); err != nil {
+	files, err := renderProjectTemplates(newTemplateFS(dest, config.Pack), data)
+	if err != nil {
 		return fmt.Errorf("failed to process templates: %w", err)
 	}
 
-	fmt.Printf("✅ Project '%s' created successfully!\n", config.Name)
-	fmt.Printf("📁 Run 'cd %s && go mod tidy' to get started\n", config.Name)
+	if err := writeFiles(dest, files); err != nil {
+		return fmt.Errorf("failed to write project files: %w", err)
+	}
+
+	for _, path := range sortedPaths(files) {
+		output.Created(filepath.Join(dest, path))
+	}
+
+	wroteEnv, err := writeDevEnv(dest, files)
+	if err != nil {
+		return fmt.Errorf("failed to write .env: %w", err)
+	}
+	if wroteEnv {
+		output.Created(filepath.Join(dest, ".env"))
+		output.Printf("🔐 Generated random development secrets in .env\n")
+	}
+
+	if err := saveBaseSnapshot(dest, files); err != nil {
+		return err
+	}
+
+	manifest := newManifest(config)
+	manifest.Files = manifestFiles(files)
+	if err := manifest.Save(dest); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ManifestName, err)
+	}
+	output.Created(filepath.Join(dest, ManifestName))
+
+	if err := GenerateOpenAPI(OpenAPIOptions{Dir: dest}); err != nil {
+		return fmt.Errorf("failed to generate OpenAPI spec: %w", err)
+	}
+
+	results, err := RunPostGenerateHooks(dest, config.Hooks, config.Pack)
+	if err != nil {
+		return err
+	}
+	PrintHookResults(results)
+	if len(results) > 0 {
+		output.Set("hooks", results)
+	}
+
+	output.Printf("✅ Project '%s' created successfully!\n", config.Name)
+	if hookSucceeded(results, "go mod tidy") {
+		output.Printf("📁 Run 'cd %s && make run' to get started\n", config.Name)
+	} else {
+		output.Printf("📁 Run 'cd %s && go mod tidy' to get started\n", config.Name)
+	}
 	return nil
 }
 
-func processEmbeddedTemplates(dest string, data ProjectData) error {
-	return processTemplateFS(templatesFS, "templates", dest, data)
+func renderProjectTemplates(fsys fs.FS, data ProjectData) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	if err := processTemplateFS(fsys, ".", ".", data, files); err != nil {
+		return nil, err
+	}
+	if data.CI != "" {
+		if err := renderCIFiles(fsys, data, files); err != nil {
+			return nil, err
+		}
+	}
+	if err := renderDeployFiles(fsys, data, files); err != nil {
+		return nil, err
+	}
+	return files, nil
 }
 
-func processTemplateFS(fsys embed.FS, src, dest string, data ProjectData) error {
-	entries, err := fsys.ReadDir(src)
+// processTemplateFS renders every template under src into files, keyed by
+// its path relative to the template root joined onto dest.
+func processTemplateFS(fsys fs.FS, src, dest string, data ProjectData, files map[string][]byte) error {
+	entries, err := fs.ReadDir(fsys, src)
 	if err != nil {
 		return err
 	}
 
 	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
+		srcPath := path.Join(src, entry.Name())
 		destPath := filepath.Join(dest, entry.Name())
 
 		if entry.IsDir() {
-			// Skip modules directory as it's for module generation
-			if entry.Name() == "modules" {
+			// Skip the module, job, CI and deploy templates, they are rendered on demand
+			if src == "." && (entry.Name() == "modules" || entry.Name() == "jobs" || entry.Name() == "ci" || entry.Name() == "deploy") {
 				continue
 			}
 
-			if err := os.MkdirAll(destPath, os.ModePerm); err != nil {
-				return err
-			}
-
-			if err := processTemplateFS(fsys, srcPath, destPath, data); err != nil {
+			if err := processTemplateFS(fsys, srcPath, destPath, data, files); err != nil {
 				return err
 			}
 			continue
 		}
 
 		// Skip files based on configuration
-		if shouldSkipFile(entry.Name(), data) {
+		if shouldSkipFile(srcPath, data) {
 			continue
 		}
 
-		// Read embedded template file
-		content, err := fsys.ReadFile(srcPath)
+		// Read template file from the first layer that has it
+		content, err := fs.ReadFile(fsys, srcPath)
 		if err != nil {
 			return err
 		}
 
 		// Process template
-		tmpl, err := template.New("file").Parse(string(content))
+		rendered, err := renderFile(srcPath, destPath, content, data)
 		if err != nil {
 			return err
 		}
+		files[destPath] = rendered
+	}
 
-		// Create target file
-		file, err := os.Create(destPath)
-		if err != nil {
+	return nil
+}
+
+// saveBaseSnapshot stores pristine copies of rendered files below baseDir.
+// They are the common ancestor 'lupettogo upgrade' merges against.
+func saveBaseSnapshot(dest string, files map[string][]byte) error {
+	if err := writeFiles(filepath.Join(dest, baseDir), files); err != nil {
+		return fmt.Errorf("failed to write template snapshot: %w", err)
+	}
+	return nil
+}
+
+func sortedPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// writeFiles writes rendered files below dest, creating directories as needed.
+func writeFiles(dest string, files map[string][]byte) error {
+	for path, content := range files {
+		target := filepath.Join(dest, path)
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
 			return err
 		}
-
-		// Execute template
-		err = tmpl.Execute(file, data)
-		file.Close()
-		if err != nil {
+		if err := os.WriteFile(target, content, 0644); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
 func shouldSkipFile(relPath string, data ProjectData) bool {
 	// Skip Docker files if Docker is disabled
-	if !data.WithDocker && (strings.Contains(relPath, "Dockerfile") || strings.Contains(relPath, "docker-compose")) {
+	if !data.WithDocker && (strings.Contains(relPath, "Dockerfile") || strings.Contains(relPath, "docker-compose") || relPath == ".dockerignore") {
 		return true
 	}
 
@@ -138,6 +262,31 @@ func shouldSkipFile(relPath string, data ProjectData) bool {
 		return true
 	}
 
+	// Skip rate limiting files unless it was asked for
+	if !data.WithRateLimit && strings.Contains(relPath, "ratelimit") {
+		return true
+	}
+
+	// Skip observability files unless metrics were asked for
+	if !data.WithMetrics && strings.HasPrefix(relPath, "internal/observability/") {
+		return true
+	}
+
+	// Skip telemetry files unless tracing was asked for
+	if !data.WithTracing && strings.HasPrefix(relPath, "internal/telemetry/") {
+		return true
+	}
+
+	// Skip the job queue and its worker unless jobs were asked for
+	if !data.WithJobs && (strings.HasPrefix(relPath, "internal/jobs/") || strings.HasPrefix(relPath, "cmd/worker/") || path.Base(relPath) == "worker.yaml") {
+		return true
+	}
+
+	// Skip the cache package until a module asks for caching
+	if !data.WithCache && strings.HasPrefix(relPath, "internal/cache/") {
+		return true
+	}
+
 	// Skip auth files if auth is disabled (when implemented)
 	if !data.WithAuth && strings.Contains(relPath, "auth") {
 		return true
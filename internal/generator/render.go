@@ -0,0 +1,243 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// templateFuncs is available to every template, project, module and pack.
+var templateFuncs = template.FuncMap{
+	"lower":        strings.ToLower,
+	"upper":        strings.ToUpper,
+	"title":        pascalCase,
+	"pascal":       pascalCase,
+	"camel":        camelCase,
+	"snake":        snakeCase,
+	"kebab":        kebabCase,
+	"pluralize":    pluralize,
+	"goType":       goType,
+	"sqlType":      sqlType,
+	"indent":       indent,
+	"quote":        strconv.Quote,
+	"join":         strings.Join,
+	"hasFieldType": hasFieldType,
+	"envPrefix":    EnvPrefix,
+	"resourceName": resourceName,
+}
+
+var templateErrorLocation = regexp.MustCompile(`template: ([^:]+):(\d+)(?::(\d+))?: (.*)`)
+
+// renderTemplate executes a single template with the shared FuncMap. Errors
+// name the template and line and quote the offending source line.
+func renderTemplate(name string, content []byte, data any) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, templateError(name, content, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, templateError(name, content, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func templateError(name string, content []byte, err error) error {
+	match := templateErrorLocation.FindStringSubmatch(err.Error())
+	if match == nil {
+		return fmt.Errorf("template %s: %w", name, err)
+	}
+
+	line, _ := strconv.Atoi(match[2])
+	msg := fmt.Sprintf("template %s:%d: %s", name, line, match[4])
+
+	lines := strings.Split(string(content), "\n")
+	if line > 0 && line <= len(lines) {
+		msg += fmt.Sprintf("\n    %d | %s", line, lines[line-1])
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// splitWords breaks identifiers like "orderItem", "OrderItem", "order_item"
+// or "order-item" into lower case words.
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(s)
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			flush()
+		case unicode.IsUpper(r):
+			// Start a new word at "aB" and at the last upper of "ABc"
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			prevUpper := i > 0 && unicode.IsUpper(runes[i-1])
+			if prevLower || (prevUpper && nextLower) {
+				flush()
+			}
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	return words
+}
+
+// commonInitialisms are written in upper case in Go identifiers.
+var commonInitialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true,
+	"sql": true, "url": true, "uuid": true, "uri": true, "html": true,
+}
+
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		if commonInitialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+func camelCase(s string) string {
+	words := splitWords(s)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0] + pascalCase(strings.Join(words[1:], "_"))
+}
+
+func snakeCase(s string) string {
+	return strings.Join(splitWords(s), "_")
+}
+
+func kebabCase(s string) string {
+	return strings.Join(splitWords(s), "-")
+}
+
+// EnvPrefix is the prefix of the environment variables a generated project
+// reads its settings from: the last element of its module path in upper
+// snake case, e.g. MY_SHOP for github.com/acme/my-shop.
+func EnvPrefix(module string) string {
+	prefix := strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.ToUpper(snakeCase(path.Base(module))))
+
+	switch {
+	case prefix == "":
+		return "APP"
+	case prefix[0] >= '0' && prefix[0] <= '9':
+		// Shells don't accept variable names starting with a digit
+		return "APP_" + prefix
+	}
+	return prefix
+}
+
+// pluralize applies English plural rules to the last word of s, keeping
+// the rest of the identifier as it is.
+func pluralize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsAny(lower[len(lower)-2:len(lower)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
+
+// fieldTypes maps the field types accepted by --fields to Go types.
+var fieldTypes = map[string]string{
+	"string": "string",
+	"text":   "string",
+	"int":    "int",
+	"int64":  "int64",
+	"uint":   "uint",
+	"float":  "float64",
+	"bool":   "bool",
+	"time":   "time.Time",
+}
+
+func goType(fieldType string) string {
+	if t, ok := fieldTypes[fieldType]; ok {
+		return t
+	}
+	return fieldType
+}
+
+// sqlType returns the column type for a field type on the given driver.
+func sqlType(driver, fieldType string) string {
+	mysql := driver == "mysql"
+	switch fieldType {
+	case "string":
+		return "varchar(255)"
+	case "text":
+		return "text"
+	case "int", "int64", "uint":
+		return "bigint"
+	case "float":
+		if mysql {
+			return "double"
+		}
+		return "double precision"
+	case "bool":
+		if mysql {
+			return "tinyint(1)"
+		}
+		return "boolean"
+	case "time":
+		if mysql {
+			return "datetime(3)"
+		}
+		return "timestamptz"
+	}
+	return ""
+}
+
+// indent prefixes every non-empty line of s with n tabs.
+func indent(n int, s string) string {
+	prefix := strings.Repeat("\t", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func hasFieldType(fields []Field, fieldType string) bool {
+	for _, field := range fields {
+		if field.Type == fieldType {
+			return true
+		}
+	}
+	return false
+}
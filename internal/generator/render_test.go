@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		in     string
+		words  []string
+		pascal string
+		camel  string
+		snake  string
+		kebab  string
+	}{
+		{"product", []string{"product"}, "Product", "product", "product", "product"},
+		{"order_item", []string{"order", "item"}, "OrderItem", "orderItem", "order_item", "order-item"},
+		{"order-item", []string{"order", "item"}, "OrderItem", "orderItem", "order_item", "order-item"},
+		{"OrderItem", []string{"order", "item"}, "OrderItem", "orderItem", "order_item", "order-item"},
+		{"HTTPServer", []string{"http", "server"}, "HTTPServer", "httpServer", "http_server", "http-server"},
+		{"userID", []string{"user", "id"}, "UserID", "userID", "user_id", "user-id"},
+		{"api_url", []string{"api", "url"}, "APIURL", "apiURL", "api_url", "api-url"},
+		{"user2FA", []string{"user2", "fa"}, "User2Fa", "user2Fa", "user2_fa", "user2-fa"},
+		{"my shop.v2", []string{"my", "shop", "v2"}, "MyShopV2", "myShopV2", "my_shop_v2", "my-shop-v2"},
+		{"__", nil, "", "", "", ""},
+	}
+	for _, tt := range tests {
+		if got := splitWords(tt.in); !reflect.DeepEqual(got, tt.words) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.in, got, tt.words)
+		}
+		if got := pascalCase(tt.in); got != tt.pascal {
+			t.Errorf("pascalCase(%q) = %q, want %q", tt.in, got, tt.pascal)
+		}
+		if got := camelCase(tt.in); got != tt.camel {
+			t.Errorf("camelCase(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := snakeCase(tt.in); got != tt.snake {
+			t.Errorf("snakeCase(%q) = %q, want %q", tt.in, got, tt.snake)
+		}
+		if got := kebabCase(tt.in); got != tt.kebab {
+			t.Errorf("kebabCase(%q) = %q, want %q", tt.in, got, tt.kebab)
+		}
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"product":   "products",
+		"status":    "statuses",
+		"box":       "boxes",
+		"batch":     "batches",
+		"wish":      "wishes",
+		"day":       "days",
+		"city":      "cities",
+		"OrderItem": "OrderItems",
+		"Category":  "Categories",
+		"y":         "ys",
+		"":          "",
+	}
+	for in, want := range tests {
+		if got := pluralize(in); got != want {
+			t.Errorf("pluralize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEnvPrefix(t *testing.T) {
+	tests := map[string]string{
+		"myapp":                   "MYAPP",
+		"my-shop":                 "MY_SHOP",
+		"github.com/acme/my-shop": "MY_SHOP",
+		"github.com/acme/MyShop":  "MY_SHOP",
+		"9lives":                  "APP_9LIVES",
+		"example.com/v2":          "V2",
+		"héllo":                   "HLLO",
+		"":                        "APP",
+		"---":                     "APP",
+	}
+	for in, want := range tests {
+		if got := EnvPrefix(in); got != want {
+			t.Errorf("EnvPrefix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	got, err := renderTemplate("model.go.tmpl", []byte(`{{pascal .Name}} {{pluralize (snake .Name)}} {{quote .Name}}`), map[string]string{"Name": "order-item"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `OrderItem order_items "order-item"`; string(got) != want {
+		t.Errorf("renderTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "execution error",
+			content: "package models\n\ntype {{.Missing}} struct{}\n",
+			want:    "template model.go.tmpl:3: executing \"model.go.tmpl\" at <.Missing>: map has no entry for key \"Missing\"\n    3 | type {{.Missing}} struct{}",
+		},
+		{
+			name:    "parse error",
+			content: "package models\n{{end}}\n",
+			want:    "template model.go.tmpl:2: unexpected {{end}}\n    2 | {{end}}",
+		},
+		{
+			name:    "unknown function",
+			content: "{{shout .Name}}",
+			want:    "template model.go.tmpl:1: function \"shout\" not defined\n    1 | {{shout .Name}}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := renderTemplate("model.go.tmpl", []byte(tt.content), map[string]string{"Name": "product"})
+			if err == nil {
+				t.Fatal("renderTemplate succeeded")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("error =\n%s\nwant\n%s", err, tt.want)
+			}
+		})
+	}
+
+	// Errors without a location are wrapped as they are
+	err := templateError("x.tmpl", nil, errors.New("boom"))
+	if !strings.HasPrefix(err.Error(), "template x.tmpl: boom") {
+		t.Errorf("templateError() = %v", err)
+	}
+}
@@ -0,0 +1,376 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adipras/lupettogo/internal/output"
+)
+
+// ProjectTemplatesDir is where a project keeps its template overrides,
+// relative to the project root.
+const ProjectTemplatesDir = ".lupettogo/templates"
+
+// Template layer names, in lookup order.
+const (
+	LayerProject  = "project"
+	LayerPack     = "pack"
+	LayerUser     = "user"
+	LayerEmbedded = "embedded"
+)
+
+type templateLayer struct {
+	name string
+	dir  string
+	fsys fs.FS
+}
+
+// TemplateInfo describes where a template resolves from.
+type TemplateInfo struct {
+	Name       string `json:"name"`
+	Layer      string `json:"layer"`
+	Path       string `json:"path,omitempty"`
+	Overridden bool   `json:"overridden"`
+}
+
+// UserTemplatesDir returns the directory holding the user's template
+// overrides, e.g. ~/.config/lupettogo/templates on Linux.
+func UserTemplatesDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "lupettogo", "templates"), nil
+}
+
+// templateLayers returns the layers templates are looked up in: the
+// project's overrides, the template pack, the user's overrides and the
+// embedded defaults. projectDir may be empty when there is no project yet
+// and pack nil when the project doesn't use one.
+func templateLayers(projectDir string, pack *TemplatePack) []templateLayer {
+	var layers []templateLayer
+
+	if projectDir != "" {
+		dir := filepath.Join(projectDir, ProjectTemplatesDir)
+		if isDir(dir) {
+			layers = append(layers, templateLayer{name: LayerProject, dir: dir, fsys: os.DirFS(dir)})
+		}
+	}
+
+	if pack != nil {
+		dir := pack.FilesDir()
+		layers = append(layers, templateLayer{name: LayerPack, dir: dir, fsys: os.DirFS(dir)})
+	}
+
+	if dir, err := UserTemplatesDir(); err == nil && isDir(dir) {
+		layers = append(layers, templateLayer{name: LayerUser, dir: dir, fsys: os.DirFS(dir)})
+	}
+
+	return append(layers, templateLayer{name: LayerEmbedded, fsys: defaultTemplatesFS()})
+}
+
+// newTemplateFS returns the layered template filesystem for a project.
+func newTemplateFS(projectDir string, pack *TemplatePack) *layeredFS {
+	return &layeredFS{layers: templateLayers(projectDir, pack)}
+}
+
+// projectTemplateFS returns the template filesystem of an existing project,
+// including the template pack recorded in its manifest.
+func projectTemplateFS(projectDir string, manifest *Manifest) (*layeredFS, error) {
+	if manifest.Template == nil {
+		return newTemplateFS(projectDir, nil), nil
+	}
+
+	pack, err := LoadProjectPack(projectDir, manifest.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template pack %s: %w", manifest.Template.Source, err)
+	}
+	if pack.Checksum != manifest.Template.Checksum {
+		output.Warnf("Template pack %s has changed since this project was generated", manifest.Template.Source)
+	}
+	return newTemplateFS(projectDir, pack), nil
+}
+
+func isDir(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// defaultTemplatesFS exposes the compiled-in templates as a filesystem.
+// Module templates live below modules/, job templates below jobs/, CI
+// templates below ci/ and deployment templates below deploy/.
+func defaultTemplatesFS() fs.FS {
+	files := memFS{}
+	for _, set := range []map[string]string{templateFiles, internalTemplates, testTemplates} {
+		for name, content := range set {
+			files[name] = []byte(content)
+		}
+	}
+	for name, content := range moduleTemplates {
+		files[path.Join("modules", name)] = []byte(content)
+	}
+	for name, content := range jobTemplates {
+		files[path.Join("jobs", name)] = []byte(content)
+	}
+	for name, content := range ciTemplates {
+		files[path.Join("ci", name)] = []byte(content)
+	}
+	for name, content := range deployTemplates {
+		files[path.Join("deploy", name)] = []byte(content)
+	}
+	return files
+}
+
+// layeredFS resolves each file from the first layer that has it. Directory
+// listings are the union of all layers.
+type layeredFS struct {
+	layers []templateLayer
+}
+
+func (l *layeredFS) Open(name string) (fs.File, error) {
+	for _, layer := range l.layers {
+		file, err := layer.fsys.Open(name)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (l *layeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	found := false
+
+	for _, layer := range l.layers {
+		layerEntries, err := fs.ReadDir(layer.fsys, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range layerEntries {
+			if !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// resolve returns the layer a template is read from.
+func (l *layeredFS) resolve(name string) (templateLayer, bool) {
+	for _, layer := range l.layers {
+		if info, err := fs.Stat(layer.fsys, name); err == nil && !info.IsDir() {
+			return layer, true
+		}
+	}
+	return templateLayer{}, false
+}
+
+// ListTemplates reports every template visible from projectDir and the
+// layer it resolves from.
+func ListTemplates(projectDir string) ([]TemplateInfo, error) {
+	fsys := newTemplateFS(projectDir, nil)
+	if manifest, err := LoadManifest(projectDir); err == nil {
+		if fsys, err = projectTemplateFS(projectDir, manifest); err != nil {
+			return nil, err
+		}
+	}
+
+	var infos []TemplateInfo
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		layer, _ := fsys.resolve(name)
+		info := TemplateInfo{Name: name, Layer: layer.name}
+		if layer.dir != "" {
+			info.Path = filepath.Join(layer.dir, filepath.FromSlash(name))
+		}
+		if layer.name != LayerEmbedded {
+			_, err := fs.Stat(defaultTemplatesFS(), name)
+			info.Overridden = err == nil
+		}
+		infos = append(infos, info)
+		return nil
+	})
+	return infos, err
+}
+
+// ExportTemplates writes the embedded default templates to dir so they can
+// be edited and used as overrides. Existing files are kept unless force is
+// set. It returns the paths written.
+func ExportTemplates(dir string, force bool) ([]string, error) {
+	var written []string
+	defaults := defaultTemplatesFS()
+
+	err := fs.WalkDir(defaults, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if _, err := os.Stat(target); err == nil && !force {
+			return nil
+		}
+
+		content, err := fs.ReadFile(defaults, name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		written = append(written, target)
+		return nil
+	})
+	return written, err
+}
+
+// memFS is a read-only in-memory filesystem keyed by slash-separated paths.
+type memFS map[string][]byte
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if content, ok := m[name]; ok {
+		return &memFile{name: path.Base(name), content: content}, nil
+	}
+	entries, err := m.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	return &memDir{name: path.Base(name), entries: entries}, nil
+}
+
+func (m memFS) ReadFile(name string) ([]byte, error) {
+	content, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), content...), nil
+}
+
+func (m memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	children := map[string]fs.DirEntry{}
+	for filePath, content := range m {
+		rest, ok := strings.CutPrefix(filePath, prefix)
+		if !ok {
+			continue
+		}
+		if child, _, isDir := strings.Cut(rest, "/"); isDir {
+			children[child] = fs.FileInfoToDirEntry(memInfo{name: child, dir: true})
+		} else {
+			children[child] = fs.FileInfoToDirEntry(memInfo{name: child, size: int64(len(content))})
+		}
+	}
+
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, entry := range children {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+type memFile struct {
+	name    string
+	content []byte
+	offset  int
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	return memInfo{name: f.name, size: int64(len(f.content))}, nil
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.offset >= len(f.content) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.content[f.offset:])
+	f.offset += n
+	return n, nil
+}
+
+func (f *memFile) Close() error { return nil }
+
+type memDir struct {
+	name    string
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return memInfo{name: d.name, dir: true}, nil }
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+func (d *memDir) Close() error { return nil }
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
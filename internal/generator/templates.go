@@ -1,22 +1,45 @@
 package generator
 
-// Template files embedded as strings to avoid go:embed module issues
+// Template files embedded as strings to avoid go:embed module issues.
+// Together with the other template maps they form the embedded layer of the
+// template filesystem, see defaultTemplatesFS.
 
 var templateFiles = map[string]string{
 	"main.go": `package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"{{.ProjectName}}/internal/config"
 	"{{.ProjectName}}/internal/server"
+	"{{.ProjectName}}/internal/telemetry"
 	"github.com/joho/godotenv"
 )
 
+// Set at build time with -ldflags "-X main.version=... -X main.commit=..."
+var (
+	version = "dev"
+	commit  = "none"
+)
+
 func main() {
+	// The container image has no shell or curl, so its HEALTHCHECK runs the
+	// binary itself with -healthcheck
+	healthcheck := flag.Bool("healthcheck", false, "Check /health of the running server and exit")
+	printConfig := flag.Bool("print-config", false, "Print the loaded configuration, secrets redacted, and exit")
+	flag.Parse()
+
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
+	if err := godotenv.Load(); err != nil && !*healthcheck {
 		log.Println("No .env file found")
 	}
 
@@ -25,53 +48,303 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if *healthcheck {
+		os.Exit(checkHealth(cfg.Server.Port))
+	}
+	if *printConfig {
+		out, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to print config: %v", err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	// Stop gracefully on Ctrl+C and on the SIGTERM sent by Docker and
+	// Kubernetes
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+{{- if .WithTracing}}
+
+	// Tracing is set up before the server so the database and router
+	// instrumentation pick up the tracer provider
+	shutdownTracing, err := telemetry.Setup(ctx, cfg.Tracing, version)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("Warning: Failed to flush traces: %v", err)
+		}
+	}()
+{{- end}}
 
 	// Start server
 	srv := server.New(cfg)
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
 
-	log.Printf("Starting server on port %s", port)
-	if err := srv.Start(":" + port); err != nil {
+	log.Printf("Starting {{.ProjectName}} %s (%s) on port %s with the %s profile", version, commit, cfg.Server.Port, cfg.Env)
+	if err := srv.Start(ctx, ":"+cfg.Server.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
+	log.Println("Server stopped")
+}
+
+func checkHealth(port string) int {
+	client := http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get("http://127.0.0.1:" + port + "/health")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintln(os.Stderr, "unhealthy:", resp.Status)
+		return 1
+	}
+	return 0
+}`,
+
+	"cmd/worker/main.go": `// Command worker runs the background jobs queued in the database, see
+// internal/jobs. It reads the same configuration as the server; run as many
+// as the queue needs.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"{{.ProjectName}}/internal/database"
+	"{{.ProjectName}}/internal/jobs"
+	"{{.ProjectName}}/internal/telemetry"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/opentelemetry/tracing"
+)
+
+// Set at build time with -ldflags "-X main.version=... -X main.commit=..."
+var (
+	version = "dev"
+	commit  = "none"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Stop taking jobs on Ctrl+C and on the SIGTERM sent by Docker and
+	// Kubernetes, and let the ones in progress finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+{{- if .WithTracing}}
+
+	shutdownTracing, err := telemetry.Setup(ctx, cfg.Tracing, version)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("Warning: Failed to flush traces: %v", err)
+		}
+	}()
+{{- end}}
+
+	// Unlike the server, the worker has nothing to do without a database
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	// Polling the queue would otherwise log a query every poll interval
+	db.Logger = db.Logger.LogMode(logger.Warn)
+{{- if .WithTracing}}
+	if cfg.Tracing.Enabled {
+		if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+			log.Printf("Warning: Failed to trace database queries: %v", err)
+		}
+	}
+{{- end}}
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	registry, err := jobs.NewRegistry(jobs.All(db)...)
+	if err != nil {
+		log.Fatalf("Failed to register jobs: %v", err)
+	}
+	pool := jobs.NewPool(jobs.NewQueue(db, cfg.Jobs), registry, cfg.Jobs)
+
+	log.Printf("Starting {{.ProjectName}} worker %s (%s) with %d workers for jobs: %s", version, commit, cfg.Jobs.Concurrency, strings.Join(registry.Types(), ", "))
+	pool.Run(ctx)
+	log.Println("Worker stopped")
 }`,
 
 	"go.mod": `module {{.ProjectName}}
 
-go 1.21
+go {{.GoVersion}}
 
 require (
-    github.com/gin-gonic/gin v1.9.1
-    github.com/joho/godotenv v1.5.1
-    github.com/sirupsen/logrus v1.9.3
-    github.com/spf13/viper v1.18.2
-    github.com/stretchr/testify v1.8.4
-    gorm.io/driver/mysql v1.5.4
-    gorm.io/driver/postgres v1.5.6
-    gorm.io/gorm v1.25.7
-)`,
-
-	".env.example": `# Server Configuration
-PORT=8080
-GIN_MODE=debug
+{{- range .Dependencies}}
+	{{.Module}} {{.Version}}
+{{- end}}
+)
+`,
+
+	".env.example": `{{- $env := envPrefix .ProjectName -}}
+# Settings are read from config/config.yaml, then config/config.<APP_ENV>.yaml,
+# then environment variables: every key maps to {{$env}}_<KEY> with dots as
+# underscores, e.g. database.host is {{$env}}_DATABASE_HOST. Keep secrets
+# here or in the environment, not in the config files.
+
+# Profile: development or production
+APP_ENV=development
+
+# The server listens on server.port from config/config.yaml unless
+# {{$env}}_SERVER_PORT, or PORT as set by platforms like Cloud Run, is set.
 
 # Database Configuration
-DB_HOST=localhost
-{{if eq .DBDriver "mysql"}}DB_PORT=3306{{else}}DB_PORT=5432{{end}}
-{{if eq .DBDriver "mysql"}}DB_USER=root{{else}}DB_USER=postgres{{end}}
-DB_PASSWORD=password
-DB_NAME={{.ProjectName}}_db
-DB_DRIVER={{.DBDriver}}
+{{$env}}_DATABASE_HOST=localhost
+{{$env}}_DATABASE_PORT={{if eq .DBDriver "mysql"}}3306{{else}}5432{{end}}
+{{$env}}_DATABASE_USER={{if eq .DBDriver "mysql"}}root{{else}}postgres{{end}}
+{{$env}}_DATABASE_PASSWORD=password
+{{$env}}_DATABASE_NAME={{.ProjectName}}_db
+{{- if .WithDocker}}
+
+# docker compose creates the database with the user, password and name above
+# and publishes it on the port above; inside the stack the app reaches it as
+# "db".
+{{- end}}
+{{- if or .WithRedis .WithRateLimit}}
+
+# Redis Configuration{{if .WithRedis}} (the "redis" service in docker-compose.yml){{end}}
+{{$env}}_REDIS_ADDR=localhost:6379
+{{- end}}
+{{- if .WithRateLimit}}
+
+# Rate limiting: REQUESTS per PERIOD for each client, in bursts of up to
+# BURST (0 means REQUESTS). Clients are keyed by ip, api_key (the
+# API_KEY_HEADER header) or user. Set STORE to redis when running more than
+# one instance.
+{{$env}}_RATELIMIT_ENABLED=true
+{{$env}}_RATELIMIT_REQUESTS=100
+{{$env}}_RATELIMIT_PERIOD=1m
+{{$env}}_RATELIMIT_BURST=0
+{{$env}}_RATELIMIT_KEY_BY=ip
+{{$env}}_RATELIMIT_API_KEY_HEADER=X-API-Key
+{{- end}}
+{{- if .WithMetrics}}
+
+# Observability: /metrics is served on the admin port, away from the API.
+# PPROF adds /debug/pprof there, for local requests only unless PPROF_TOKEN
+# is set and sent as "Authorization: Bearer <token>".
+{{$env}}_METRICS_ENABLED=true
+{{$env}}_METRICS_ADMIN_PORT=9090
+{{$env}}_METRICS_PPROF=false
+{{$env}}_METRICS_PPROF_TOKEN=
+{{- end}}
+{{- if .WithTracing}}
+
+# Tracing: spans are sent over OTLP/HTTP to the endpoint, e.g. an
+# OpenTelemetry Collector or Jaeger. The development profile sends them
+# without TLS, which is what a collector on the same machine usually expects.
+{{$env}}_TRACING_ENABLED=false
+{{$env}}_TRACING_SERVICE_NAME={{.ProjectName}}
+{{$env}}_TRACING_ENDPOINT=localhost:4318
+{{- end}}
+{{- if .WithJobs}}
+
+# Background jobs, run by the worker (go run ./cmd/worker): up to
+# CONCURRENCY jobs at once, each attempt within TIMEOUT. Failed jobs are
+# retried with exponential backoff and dead-lettered after MAX_ATTEMPTS.
+{{$env}}_JOBS_CONCURRENCY=4
+{{$env}}_JOBS_TIMEOUT=5m
+{{$env}}_JOBS_MAX_ATTEMPTS=5
+{{- end}}
 
 # JWT Configuration
-JWT_SECRET=your-super-secret-jwt-key-here
-JWT_EXPIRES_IN=24h
-
-# API Configuration
-API_VERSION=v1`,
+{{$env}}_JWT_SECRET=your-super-secret-jwt-key-here
+{{$env}}_JWT_EXPIRES_IN=24h`,
+
+	"config/config.yaml": `{{- $env := envPrefix .ProjectName -}}
+# Settings shared by every profile. config.<APP_ENV>.yaml is merged over this
+# file and environment variables override both, e.g. {{$env}}_SERVER_PORT for
+# server.port. Secrets belong in the environment, not here.
+server:
+  port: "8080"
+
+database:
+  driver: {{.DBDriver}}
+  name: {{.ProjectName}}_db
+
+jwt:
+  expires_in: 24h
+
+api:
+  version: v1
+
+# Origins are matched exactly or with a wildcard subdomain such as
+# https://*.example.com; none refuses cross-origin requests. In the
+# environment they are comma separated.
+cors:
+  allowed_methods: [GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS]
+  allowed_headers: [Authorization, Content-Type, Accept, X-Requested-With]
+  allow_credentials: false
+  max_age: 12h
+`,
+
+	"config/config.development.yaml": `# APP_ENV=development, the default: Gin debug mode and local services.
+server:
+  mode: debug
+
+database:
+  host: localhost
+
+cors:
+  allowed_origins: ["http://localhost:3000"]
+{{- if .WithTracing}}
+
+tracing:
+  insecure: true
+{{- end}}
+`,
+
+	"config/config.production.yaml": `{{- $env := envPrefix .ProjectName -}}
+# APP_ENV=production: Gin release mode. The server refuses to start until
+# the database password and JWT secret are set, e.g. with
+# {{$env}}_DATABASE_PASSWORD and {{$env}}_JWT_SECRET.
+server:
+  mode: release
+
+cors:
+  allowed_origins: []
+{{- if .WithRateLimit}}{{if .WithRedis}}
+
+ratelimit:
+  store: redis
+{{- end}}{{end}}
+{{- if .WithTracing}}
+
+tracing:
+  sample_ratio: 0.1
+{{- end}}
+`,
 
 	".gitignore": `# Binaries
 *.exe
@@ -80,6 +353,9 @@ API_VERSION=v1`,
 *.so
 *.dylib
 {{.ProjectName}}
+{{- if .WithJobs}}
+{{.ProjectName}}-worker
+{{- end}}
 
 # Test binary
 *.test
@@ -122,13 +398,13 @@ A production-ready Golang SaaS starter project generated by LupettoGo 🐺.
 
 ### Prerequisites
 
-- Go 1.21 or higher
+- Go {{.GoVersion}} or higher
 - PostgreSQL or MySQL database (optional)
 
 ### Installation
 
 1. Clone this project (if generated separately)
-2. Copy environment variables:
+2. Create ` + "`" + `.env` + "`" + `, unless ` + "`" + `lupettogo init` + "`" + ` already did with random secrets:
    ` + "`" + `bash
    cp .env.example .env
    ` + "`" + `
@@ -138,6 +414,19 @@ A production-ready Golang SaaS starter project generated by LupettoGo 🐺.
    go mod tidy
    ` + "`" + `
 
+### Configuration
+
+Settings are read in this order, later sources winning:
+
+1. Defaults in ` + "`" + `internal/config/config.go` + "`" + `
+2. ` + "`" + `config/config.yaml` + "`" + `
+3. ` + "`" + `config/config.<APP_ENV>.yaml` + "`" + `, where ` + "`" + `APP_ENV` + "`" + ` is ` + "`" + `development` + "`" + ` (the default) or ` + "`" + `production` + "`" + `
+4. Environment variables, including ` + "`" + `.env` + "`" + `: each key maps to ` + "`" + `{{envPrefix .ProjectName}}_<KEY>` + "`" + ` with dots replaced by underscores, e.g. ` + "`" + `database.host` + "`" + ` is ` + "`" + `{{envPrefix .ProjectName}}_DATABASE_HOST` + "`" + `. ` + "`" + `PORT` + "`" + ` is read too when ` + "`" + `{{envPrefix .ProjectName}}_SERVER_PORT` + "`" + ` isn't set.
+
+The server validates the result on startup and lists every missing or malformed setting with its environment variable. In production the database password and JWT secret are required, and in release mode example values such as ` + "`" + `password` + "`" + ` are refused.
+
+Any setting can be read from a file instead, as with Docker and Kubernetes secrets, by setting its variable with a ` + "`" + `_FILE` + "`" + ` suffix, e.g. ` + "`" + `{{envPrefix .ProjectName}}_JWT_SECRET_FILE=/run/secrets/jwt_secret` + "`" + `. Secrets are ` + "`" + `config.Secret` + "`" + ` values that print as ` + "`" + `[REDACTED]` + "`" + `; ` + "`" + `go run . -print-config` + "`" + ` shows the loaded configuration that way.
+
 ### Running the Application
 
 ` + "`" + `bash
@@ -155,6 +444,22 @@ The server will start on ` + "`" + `http://localhost:8080` + "`" + `
 
 - ` + "`" + `GET /health` + "`" + ` - Health check endpoint
 - ` + "`" + `GET /api/v1/example` + "`" + ` - Example API endpoint
+- ` + "`" + `GET /openapi.yaml` + "`" + ` - OpenAPI spec (regenerate with ` + "`" + `make openapi` + "`" + `)
+- ` + "`" + `GET /swagger` + "`" + ` - Swagger UI (debug mode only)
+{{- if .WithJobs}}
+
+### Background Jobs
+
+Work that shouldn't hold up a request goes to the job queue in ` + "`" + `internal/jobs` + "`" + `, a table in the database that the worker takes jobs from:
+
+` + "`" + `bash
+go run ./cmd/worker   # or: make worker
+` + "`" + `
+
+Enqueue a job with its typed helper, e.g. ` + "`" + `queue.EnqueueExample(ctx, jobs.ExamplePayload{Message: "hi"})` + "`" + ` where ` + "`" + `queue := jobs.NewQueue(db, cfg.Jobs)` + "`" + `; ` + "`" + `queue.WithTx(tx)` + "`" + ` enqueues inside a transaction. Failed attempts are retried with exponential backoff; after ` + "`" + `jobs.max_attempts` + "`" + `, or straight away for errors wrapped with ` + "`" + `jobs.Permanent` + "`" + `, the job is dead-lettered: it stays in the table with status ` + "`" + `dead` + "`" + ` and its last error, for ` + "`" + `Queue.Dead` + "`" + ` and ` + "`" + `Queue.Retry` + "`" + `. Any number of workers can run at once.
+
+Add a job type with ` + "`" + `lupettogo generate job <name>` + "`" + ` and register it in ` + "`" + `jobs.All` + "`" + ` in ` + "`" + `internal/jobs/registry.go` + "`" + `.
+{{- end}}
 
 ## Generated by LupettoGo 🐺
 
@@ -162,50 +467,212 @@ This project was scaffolded using LupettoGo - a CLI tool for creating production
 
 *With the little wolf, no project is too big.*`,
 
-	"Dockerfile": `# Build stage
-FROM golang:1.21-alpine AS builder
+	"Dockerfile": `# syntax=docker/dockerfile:1
 
-WORKDIR /app
+# Build stage, run on the build host's platform and cross-compiled
+FROM --platform=$BUILDPLATFORM {{.BuilderImage}} AS builder
+
+WORKDIR /src
 
-# Copy go mod files
+# Download modules first so they are cached until go.mod changes
 COPY go.mod go.sum ./
-RUN go mod download
+RUN --mount=type=cache,target=/go/pkg/mod \
+    go mod download
 
-# Copy source code
 COPY . .
 
-# Build the application
-RUN CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -o main .
-
-# Final stage
-FROM alpine:latest
+ARG TARGETOS
+ARG TARGETARCH
+ARG VERSION=dev
+ARG COMMIT=none
 
-RUN apk --no-cache add ca-certificates tzdata
-WORKDIR /root/
+RUN --mount=type=cache,target=/go/pkg/mod \
+    --mount=type=cache,target=/root/.cache/go-build \
+    CGO_ENABLED=0 GOOS=$TARGETOS GOARCH=$TARGETARCH \
+    go build -trimpath -ldflags "-s -w -X main.version=$VERSION -X main.commit=$COMMIT" -o /out/app .
+{{- if .WithJobs}}
 
-# Copy the binary from builder stage
-COPY --from=builder /app/main .
+RUN --mount=type=cache,target=/go/pkg/mod \
+    --mount=type=cache,target=/root/.cache/go-build \
+    CGO_ENABLED=0 GOOS=$TARGETOS GOARCH=$TARGETARCH \
+    go build -trimpath -ldflags "-s -w -X main.version=$VERSION -X main.commit=$COMMIT" -o /out/worker ./cmd/worker
+{{- end}}
 
-# Copy .env.example as template
-COPY --from=builder /app/.env.example .
+# Final stage: no shell, no package manager, runs as an unprivileged user
+FROM {{.RuntimeImage}}
 
+WORKDIR /app
+COPY --from=builder /out/app /app/app
+{{- if .WithJobs}}
+# The job worker, run with --entrypoint /app/worker
+COPY --from=builder /out/worker /app/worker
+{{- end}}
+COPY --from=builder /src/openapi.yaml /app/openapi.yaml
+COPY --from=builder /src/config /app/config
+
+# Override with -e APP_ENV=... to run another profile
+ENV APP_ENV=production
+
+USER nonroot:nonroot
 EXPOSE 8080
+{{- if .WithMetrics}}
+# Admin port: /metrics and, when enabled, /debug/pprof
+EXPOSE 9090
+{{- end}}
 
-CMD ["./main"]`,
+HEALTHCHECK --interval=30s --timeout=5s --start-period=10s --retries=3 \
+    CMD ["/app/app", "-healthcheck"]
+
+ENTRYPOINT ["/app/app"]`,
+
+	".dockerignore": `# Keep the build context small and free of secrets
+.git
+.github
+.gitlab-ci.yml
+.lupettogo
+.env
+.env.*
+!.env.example
+*.md
+Dockerfile
+.dockerignore
+docker-compose*.yml
+vendor
+bin
+coverage.out
+coverage.html
+{{.ProjectName}}
+{{- if .WithJobs}}
+{{.ProjectName}}-worker
+{{- end}}
+`,
+
+	"docker-compose.yml": `{{- $env := envPrefix .ProjectName -}}
+# Local development stack: the app{{if .WithJobs}}, its job worker{{end}}, {{.DBDriver}}{{if .WithRedis}} and Redis{{end}}.
+# Credentials come from .env (copy .env.example), falling back to the defaults below.
+services:
+  app:
+    build: .
+    ports:
+      - "${ {{- $env}}_SERVER_PORT:-8080}:8080"
+{{- if .WithMetrics}}
+      - "${ {{- $env}}_METRICS_ADMIN_PORT:-9090}:9090"
+{{- end}}
+    environment:{{if .WithJobs}} &app-environment{{end}}
+      APP_ENV: ${APP_ENV:-development}
+      {{$env}}_SERVER_PORT: "8080"
+      {{$env}}_DATABASE_HOST: db
+      {{$env}}_DATABASE_PORT: "{{if eq .DBDriver "mysql"}}3306{{else}}5432{{end}}"
+      {{$env}}_DATABASE_USER: ${ {{- $env}}_DATABASE_USER:-{{if eq .DBDriver "mysql"}}root{{else}}postgres{{end}}}
+      {{$env}}_DATABASE_PASSWORD: ${ {{- $env}}_DATABASE_PASSWORD:-password}
+      {{$env}}_DATABASE_NAME: ${ {{- $env}}_DATABASE_NAME:-{{.ProjectName}}_db}
+      {{$env}}_JWT_SECRET: ${ {{- $env}}_JWT_SECRET:-}
+{{- if .WithRedis}}
+      {{$env}}_REDIS_ADDR: redis:6379
+{{- if .WithRateLimit}}
+      {{$env}}_RATELIMIT_STORE: redis
+{{- end}}
+{{- end}}
+    depends_on:
+      db:
+        condition: service_healthy
+{{- if .WithRedis}}
+      redis:
+        condition: service_healthy
+{{- end}}
+    restart: unless-stopped
+{{- if .WithJobs}}
+
+  worker:
+    build: .
+    entrypoint: ["/app/worker"]
+    environment: *app-environment
+    # The image's HEALTHCHECK probes the HTTP server, which the worker doesn't run
+    healthcheck:
+      disable: true
+    depends_on:
+      db:
+        condition: service_healthy
+    restart: unless-stopped
+{{- end}}
+
+  db:
+{{- if eq .DBDriver "mysql"}}
+    image: {{.DatabaseImage}}
+    environment:
+      MYSQL_ROOT_PASSWORD: ${ {{- $env}}_DATABASE_PASSWORD:-password}
+      MYSQL_DATABASE: ${ {{- $env}}_DATABASE_NAME:-{{.ProjectName}}_db}
+    ports:
+      - "${ {{- $env}}_DATABASE_PORT:-3306}:3306"
+    volumes:
+      - db-data:/var/lib/mysql
+    healthcheck:
+      test: ["CMD-SHELL", "mysqladmin ping -h 127.0.0.1 -uroot -p$$MYSQL_ROOT_PASSWORD --silent"]
+{{- else}}
+    image: {{.DatabaseImage}}
+    environment:
+      POSTGRES_USER: ${ {{- $env}}_DATABASE_USER:-postgres}
+      POSTGRES_PASSWORD: ${ {{- $env}}_DATABASE_PASSWORD:-password}
+      POSTGRES_DB: ${ {{- $env}}_DATABASE_NAME:-{{.ProjectName}}_db}
+    ports:
+      - "${ {{- $env}}_DATABASE_PORT:-5432}:5432"
+    volumes:
+      - db-data:/var/lib/postgresql/data
+    healthcheck:
+      test: ["CMD-SHELL", "pg_isready -U $$POSTGRES_USER -d $$POSTGRES_DB"]
+{{- end}}
+      interval: 5s
+      timeout: 5s
+      retries: 10
+{{- if .WithRedis}}
+
+  redis:
+    image: {{.RedisImage}}
+    ports:
+      - "${REDIS_PORT:-6379}:6379"
+    volumes:
+      - redis-data:/data
+    healthcheck:
+      test: ["CMD", "redis-cli", "ping"]
+      interval: 5s
+      timeout: 3s
+      retries: 10
+{{- end}}
+
+volumes:
+  db-data:
+{{- if .WithRedis}}
+  redis-data:
+{{- end}}
+`,
 
 	"Makefile": `# {{.ProjectName}} Makefile
 
 # Variables
 BINARY_NAME={{.ProjectName}}
-DOCKER_IMAGE={{.ProjectName}}:latest
+VERSION ?= $(shell git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT ?= $(shell git rev-parse --short HEAD 2>/dev/null || echo none)
+LDFLAGS = -s -w -X main.version=$(VERSION) -X main.commit=$(COMMIT)
+DOCKER_IMAGE ?= {{.ProjectName}}
+DOCKER_TAG ?= $(VERSION)
+PLATFORMS ?= linux/amd64,linux/arm64
 
 # Build the application
 build:
-	go build -o $(BINARY_NAME) main.go
+	go build -trimpath -ldflags "$(LDFLAGS)" -o $(BINARY_NAME) .
+{{- if .WithJobs}}
+	go build -trimpath -ldflags "$(LDFLAGS)" -o $(BINARY_NAME)-worker ./cmd/worker
+{{- end}}
 
 # Run the application
 run:
 	go run main.go
+{{- if .WithJobs}}
+
+# Run the background job worker
+worker:
+	go run ./cmd/worker
+{{- end}}
 
 # Run tests
 test:
@@ -228,6 +695,10 @@ fmt:
 tidy:
 	go mod tidy
 
+# Generate OpenAPI spec from handler annotations
+openapi:
+	lupettogo openapi
+
 # Install dependencies
 deps:
 	go mod download
@@ -235,16 +706,39 @@ deps:
 # Clean build artifacts
 clean:
 	rm -f $(BINARY_NAME)
+{{- if .WithJobs}}
+	rm -f $(BINARY_NAME)-worker
+{{- end}}
 	rm -f coverage.out
 	rm -f coverage.html
 
-# Docker build
+# Docker build for the local platform
 docker-build:
-	docker build -t $(DOCKER_IMAGE) .
+	docker build --build-arg VERSION=$(VERSION) --build-arg COMMIT=$(COMMIT) -t $(DOCKER_IMAGE):$(DOCKER_TAG) .
+
+# Multi-arch build for PLATFORMS, pushed to the registry in DOCKER_IMAGE
+docker-buildx:
+	docker buildx build --platform $(PLATFORMS) --build-arg VERSION=$(VERSION) --build-arg COMMIT=$(COMMIT) \
+		-t $(DOCKER_IMAGE):$(DOCKER_TAG) --push .
 
 # Docker run
 docker-run:
-	docker run -p 8080:8080 $(DOCKER_IMAGE)
+	docker run --rm -p 8080:8080 $(DOCKER_IMAGE):$(DOCKER_TAG)
+
+{{- if .WithDocker}}
+
+# Start the app and its services with docker compose
+up:
+	docker compose up -d --build
+
+# Stop the stack
+down:
+	docker compose down
+
+# Follow the logs of the stack
+logs:
+	docker compose logs -f
+{{- end}}
 
 # Development setup
 dev-setup:
@@ -256,15 +750,25 @@ help:
 	@echo "Available commands:"
 	@echo "  build         - Build the application"
 	@echo "  run           - Run the application"
+{{- if .WithJobs}}
+	@echo "  worker        - Run the background job worker"
+{{- end}}
 	@echo "  test          - Run tests"
 	@echo "  test-coverage - Run tests with coverage"
 	@echo "  lint          - Run linter"
 	@echo "  fmt           - Format code"
 	@echo "  tidy          - Tidy dependencies"
+	@echo "  openapi       - Generate OpenAPI spec"
 	@echo "  clean         - Clean build artifacts"
 	@echo "  docker-build  - Build Docker image"
+	@echo "  docker-buildx - Build and push a multi-arch image (PLATFORMS)"
 	@echo "  docker-run    - Run Docker container"
+{{- if .WithDocker}}
+	@echo "  up            - Start the docker compose stack"
+	@echo "  down          - Stop the docker compose stack"
+	@echo "  logs          - Follow the docker compose logs"
+{{- end}}
 	@echo "  dev-setup     - Setup development environment"
 
-.PHONY: build run test test-coverage lint fmt tidy deps clean docker-build docker-run dev-setup help`,
+.PHONY: build run {{if .WithJobs}}worker {{end}}test test-coverage lint fmt tidy openapi deps clean docker-build docker-buildx docker-run {{if .WithDocker}}up down logs {{end}}dev-setup help`,
 }
\ No newline at end of file
@@ -3,15 +3,180 @@ package generator
 // Test template files
 
 var testTemplates = map[string]string{
+	"internal/config/config_test.go": `{{- $env := envPrefix .ProjectName -}}
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inConfigDir runs the test in a directory holding the given config files.
+func inConfigDir(t *testing.T, files map[string]string) {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	t.Chdir(dir)
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	inConfigDir(t, nil)
+	t.Setenv("APP_ENV", "")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, Development, cfg.Env)
+	assert.Equal(t, "8080", cfg.Server.Port)
+	assert.Equal(t, "{{.DBDriver}}", cfg.Database.Driver)
+}
+
+func TestLoad_ProfileOverridesBaseFile(t *testing.T) {
+	inConfigDir(t, map[string]string{
+		"config.yaml":            "server:\n  mode: debug\napi:\n  version: v2\n",
+		"config.production.yaml": "server:\n  mode: release\n",
+	})
+	t.Setenv("APP_ENV", Production)
+	t.Setenv("{{$env}}_DATABASE_PASSWORD", "a-real-password")
+	t.Setenv("{{$env}}_JWT_SECRET", "0123456789abcdef0123456789abcdef")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "release", cfg.Server.Mode)
+	assert.Equal(t, "v2", cfg.API.Version)
+}
+
+func TestLoad_EnvOverridesFiles(t *testing.T) {
+	inConfigDir(t, map[string]string{
+		"config.yaml": "database:\n  host: from-file\n",
+	})
+	t.Setenv("APP_ENV", "")
+	t.Setenv("{{$env}}_DATABASE_HOST", "from-env")
+	t.Setenv("{{$env}}_CORS_ALLOWED_ORIGINS", "https://a.example.com,https://b.example.com")
+	t.Setenv("PORT", "9000")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Database.Host)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.AllowedOrigins)
+	assert.Equal(t, "9000", cfg.Server.Port)
+}
+
+func TestLoad_ReportsEveryInvalidSetting(t *testing.T) {
+	inConfigDir(t, nil)
+	t.Setenv("APP_ENV", Production)
+	t.Setenv("{{$env}}_SERVER_PORT", "http")
+	t.Setenv("{{$env}}_JWT_EXPIRES_IN", "a day")
+
+	_, err := Load()
+	require.Error(t, err)
+	for _, want := range []string{
+		"invalid production configuration",
+		"server.port ({{$env}}_SERVER_PORT) must be a port number",
+		"jwt.expires_in ({{$env}}_JWT_EXPIRES_IN) must be a duration",
+		"database.password ({{$env}}_DATABASE_PASSWORD) is required in production",
+		"jwt.secret ({{$env}}_JWT_SECRET) is required in production",
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "{{$env}}_DATABASE_HOST", EnvName("database.host"))
+}`,
+
+	"internal/config/secret_test.go": `{{- $env := envPrefix .ProjectName -}}
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret_Redacted(t *testing.T) {
+	cfg := Config{JWT: JWTConfig{Secret: "s3cret-value"}}
+
+	var logged bytes.Buffer
+	slog.New(slog.NewTextHandler(&logged, nil)).Info("config", "secret", cfg.JWT.Secret)
+	dumped, err := json.Marshal(cfg)
+	require.NoError(t, err)
+
+	for _, dump := range []string{
+		fmt.Sprintf("%v", cfg),
+		fmt.Sprintf("%+v", cfg),
+		fmt.Sprintf("%#v", cfg),
+		string(dumped),
+		logged.String(),
+	} {
+		assert.NotContains(t, dump, "s3cret-value")
+		assert.Contains(t, dump, "[REDACTED]")
+	}
+	assert.Equal(t, "s3cret-value", cfg.JWT.Secret.Value())
+	assert.Equal(t, "", Secret("").String())
+}
+
+func TestLoad_ReadsSecretFiles(t *testing.T) {
+	inConfigDir(t, map[string]string{"jwt_secret": "from-a-file\n"})
+	t.Setenv("APP_ENV", "")
+	t.Setenv("{{$env}}_JWT_SECRET_FILE", "jwt_secret")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "from-a-file", cfg.JWT.Secret.Value())
+}
+
+func TestLoad_SecretFileErrors(t *testing.T) {
+	inConfigDir(t, map[string]string{"db_password": "x"})
+	t.Setenv("APP_ENV", "")
+	t.Setenv("{{$env}}_DATABASE_PASSWORD", "y")
+	t.Setenv("{{$env}}_DATABASE_PASSWORD_FILE", "db_password")
+	t.Setenv("{{$env}}_JWT_SECRET_FILE", filepath.Join("missing", "jwt_secret"))
+
+	_, err := Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "both {{$env}}_DATABASE_PASSWORD and {{$env}}_DATABASE_PASSWORD_FILE are set")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestValidate_ReleaseRefusesPlaceholders(t *testing.T) {
+	inConfigDir(t, nil)
+	t.Setenv("APP_ENV", "")
+	t.Setenv("{{$env}}_SERVER_MODE", "release")
+	t.Setenv("{{$env}}_DATABASE_PASSWORD", "password")
+	t.Setenv("{{$env}}_JWT_SECRET", "your-super-secret-jwt-key-here")
+
+	_, err := Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database.password ({{$env}}_DATABASE_PASSWORD) is an example value")
+	assert.Contains(t, err.Error(), "jwt.secret ({{$env}}_JWT_SECRET) is an example value")
+
+	t.Setenv("{{$env}}_DATABASE_PASSWORD", "a-real-password")
+	t.Setenv("{{$env}}_JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	_, err = Load()
+	assert.NoError(t, err)
+}`,
+
 	"internal/handlers/example_handler_test.go": `package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
-	"{{.ProjectName}}/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,7 +186,7 @@ type MockExampleService struct {
 	mock.Mock
 }
 
-func (m *MockExampleService) GetExample() map[string]interface{} {
+func (m *MockExampleService) GetExample(ctx context.Context) map[string]interface{} {
 	args := m.Called()
 	return args.Get(0).(map[string]interface{})
 }
@@ -67,9 +232,1194 @@ func TestExampleHandler_GetExample(t *testing.T) {
 	mockService.AssertExpectations(t)
 }`,
 
+	"internal/middleware/cors_test.go": `package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSRouter(cfg config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS(cfg))
+	router.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, "/ping", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		req.Header.Set("Access-Control-Request-Headers", "Authorization")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+var testCORSConfig = config.CORSConfig{
+	AllowedOrigins:   []string{"https://app.example.com", "https://*.tenant.example.com"},
+	AllowedMethods:   []string{"GET", "POST"},
+	AllowedHeaders:   []string{"Authorization", "Content-Type"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	router := newCORSRouter(testCORSConfig)
+
+	w := corsRequest(router, http.MethodOptions, "https://app.example.com", true)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+}
+
+func TestCORS_AllowedOrigins(t *testing.T) {
+	router := newCORSRouter(testCORSConfig)
+
+	tests := []struct {
+		name   string
+		origin string
+	}{
+		{"exact", "https://app.example.com"},
+		{"wildcard subdomain", "https://acme.tenant.example.com"},
+		{"nested subdomain", "https://eu.acme.tenant.example.com"},
+		{"case insensitive", "https://APP.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := corsRequest(router, http.MethodGet, tt.origin, false)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.origin, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Contains(t, w.Header().Values("Vary"), "Origin")
+		})
+	}
+}
+
+func TestCORS_RejectedOrigins(t *testing.T) {
+	router := newCORSRouter(testCORSConfig)
+
+	origins := []string{
+		"https://evil.com",
+		"http://app.example.com",
+		"https://app.example.com.evil.com",
+		"https://tenant.example.com",
+		"https://evil.com/.tenant.example.com",
+	}
+	for _, origin := range origins {
+		t.Run(origin, func(t *testing.T) {
+			w := corsRequest(router, http.MethodOptions, origin, true)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+			w = corsRequest(router, http.MethodGet, origin, false)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+		})
+	}
+}
+
+func TestCORS_WildcardNeverAllowsCredentials(t *testing.T) {
+	cfg := testCORSConfig
+	cfg.AllowedOrigins = []string{"*"}
+	router := newCORSRouter(cfg)
+
+	w := corsRequest(router, http.MethodGet, "https://anywhere.dev", false)
+
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_SameOriginRequest(t *testing.T) {
+	router := newCORSRouter(testCORSConfig)
+
+	w := corsRequest(router, http.MethodGet, "", false)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}`,
+
+	"internal/middleware/ratelimit_test.go": `package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock tests move by hand.
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func newRateLimitRouter(store RateLimitStore, limit RateLimit) (*gin.Engine, *RateLimiter, *fakeClock) {
+	gin.SetMode(gin.TestMode)
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	limiter := NewRateLimiter(store, KeyByAPIKey("X-API-Key"), limit)
+	limiter.now = clock.Now
+
+	router := gin.New()
+	router.Use(limiter.Handler())
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	router.GET("/items", ok)
+	router.POST("/login", ok)
+	return router, limiter, clock
+}
+
+func rateLimitRequest(router *gin.Engine, method, path, apiKey string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_BurstThenReject(t *testing.T) {
+	router, _, _ := newRateLimitRouter(NewMemoryStore(), RateLimit{Requests: 60, Period: time.Minute, Burst: 3})
+
+	for i := 2; i >= 0; i-- {
+		w := rateLimitRequest(router, http.MethodGet, "/items", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(i), w.Header().Get("RateLimit-Remaining"))
+	}
+
+	w := rateLimitRequest(router, http.MethodGet, "/items", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, "3", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "60;w=60;burst=3", w.Header().Get("RateLimit-Policy"))
+}
+
+func TestRateLimit_RefillsOverTime(t *testing.T) {
+	router, _, clock := newRateLimitRouter(NewMemoryStore(), RateLimit{Requests: 10, Period: 10 * time.Second, Burst: 1})
+
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, http.MethodGet, "/items", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequest(router, http.MethodGet, "/items", "").Code)
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequest(router, http.MethodGet, "/items", "").Code)
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, http.MethodGet, "/items", "").Code)
+}
+
+func TestRateLimit_SeparateBucketsPerKey(t *testing.T) {
+	router, _, _ := newRateLimitRouter(NewMemoryStore(), RateLimit{Requests: 1, Period: time.Minute})
+
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, http.MethodGet, "/items", "alice").Code)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequest(router, http.MethodGet, "/items", "alice").Code)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, http.MethodGet, "/items", "bob").Code)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, http.MethodGet, "/items", "").Code)
+}
+
+func TestRateLimit_RouteOverride(t *testing.T) {
+	router, limiter, clock := newRateLimitRouter(NewMemoryStore(), RateLimit{Requests: 100, Period: time.Minute})
+	limiter.Route(http.MethodPost, "/login", RateLimit{Requests: 2, Period: time.Hour})
+
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, http.MethodPost, "/login", "").Code)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, http.MethodPost, "/login", "").Code)
+
+	w := rateLimitRequest(router, http.MethodPost, "/login", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1800", w.Header().Get("Retry-After"))
+
+	// Other routes keep the default limit and their own bucket
+	w = rateLimitRequest(router, http.MethodGet, "/items", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "99", w.Header().Get("RateLimit-Remaining"))
+
+	clock.Advance(30 * time.Minute)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, http.MethodPost, "/login", "").Code)
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, RateLimit, time.Time) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store unavailable")
+}
+
+func TestRateLimit_FailsOpen(t *testing.T) {
+	router, _, _ := newRateLimitRouter(failingStore{}, RateLimit{Requests: 1, Period: time.Minute})
+
+	w := rateLimitRequest(router, http.MethodGet, "/items", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestMemoryStore_DropsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	limit := RateLimit{Requests: 1, Period: time.Second}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, _ = store.Take(context.Background(), "a", limit, now)
+	_, _ = store.Take(context.Background(), "b", limit, now.Add(2*sweepInterval))
+
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "b")
+}
+
+func TestRateLimit_NilLimiterAllows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var limiter *RateLimiter
+	limiter.Route(http.MethodGet, "/items", RateLimit{Requests: 1, Period: time.Minute})
+
+	router := gin.New()
+	router.Use(limiter.Handler())
+	router.GET("/items", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	for i := 0; i < 3; i++ {
+		w := rateLimitRequest(router, http.MethodGet, "/items", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func newRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewRedisStore(client, "ratelimit:"), server
+}
+
+func TestRedisStore_MatchesMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	redisStore, server := newRedisStore(t)
+	memoryStore := NewMemoryStore()
+	limit := RateLimit{Requests: 10, Period: 10 * time.Second, Burst: 3}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Drain the bucket, then wait for half a token and for a whole one
+	steps := []struct {
+		wait    time.Duration
+		allowed bool
+	}{
+		{0, true}, {0, true}, {0, true}, {0, false},
+		{500 * time.Millisecond, false}, {500 * time.Millisecond, true}, {0, false},
+	}
+	for i, step := range steps {
+		now = now.Add(step.wait)
+		want, err := memoryStore.Take(ctx, "ip:192.0.2.1", limit, now)
+		require.NoError(t, err)
+		got, err := redisStore.Take(ctx, "ip:192.0.2.1", limit, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, step.allowed, got.Allowed, "request %d", i)
+		assert.Equal(t, want.Allowed, got.Allowed, "request %d", i)
+		assert.Equal(t, want.Remaining, got.Remaining, "request %d", i)
+		assert.InDelta(t, want.RetryAfter, got.RetryAfter, float64(time.Millisecond), "request %d", i)
+		assert.InDelta(t, want.Reset, got.Reset, float64(time.Millisecond), "request %d", i)
+	}
+
+	// The bucket expires once it would be full again
+	require.True(t, server.Exists("ratelimit:ip:192.0.2.1"))
+	ttl := server.TTL("ratelimit:ip:192.0.2.1")
+	assert.Greater(t, ttl, time.Duration(0))
+	assert.LessOrEqual(t, ttl, 3*time.Second+time.Second)
+	server.FastForward(ttl)
+	assert.False(t, server.Exists("ratelimit:ip:192.0.2.1"))
+}
+
+func TestRedisStore_SharedBetweenInstances(t *testing.T) {
+	store, _ := newRedisStore(t)
+	limit := RateLimit{Requests: 2, Period: time.Minute}
+	first, _, _ := newRateLimitRouter(store, limit)
+	second, _, _ := newRateLimitRouter(store, limit)
+
+	assert.Equal(t, http.StatusOK, rateLimitRequest(first, http.MethodGet, "/items", "alice").Code)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(second, http.MethodGet, "/items", "alice").Code)
+
+	w := rateLimitRequest(first, http.MethodGet, "/items", "alice")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, rateLimitRequest(second, http.MethodGet, "/items", "bob").Code)
+}
+
+func TestRedisStore_FailsOpenWhenUnreachable(t *testing.T) {
+	store, server := newRedisStore(t)
+	router, _, _ := newRateLimitRouter(store, RateLimit{Requests: 1, Period: time.Minute})
+	server.Close()
+
+	w := rateLimitRequest(router, http.MethodGet, "/items", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}`,
+
+	"internal/observability/metrics_test.go": `package observability
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"{{.ProjectName}}/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMetricsRouter(m *Metrics) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "user")
+	})
+	return router
+}
+
+func TestMiddleware_RecordsByRoute(t *testing.T) {
+	m := NewMetrics()
+	router := newMetricsRouter(m)
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/users/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.requestDuration))
+}
+
+func TestAdminHandler_ServesMetrics(t *testing.T) {
+	m := NewMetrics()
+	req, _ := http.NewRequest("GET", "/users/1", nil)
+	newMetricsRouter(m).ServeHTTP(httptest.NewRecorder(), req)
+
+	handler := AdminHandler(config.MetricsConfig{}, m)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.True(t, strings.Contains(body, ` + "`" + `http_requests_total{method="GET",route="/users/:id",status="200"} 1` + "`" + `))
+	assert.True(t, strings.Contains(body, "go_goroutines"))
+}
+
+func TestAdminHandler_Pprof(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.MetricsConfig
+		remote string
+		auth   string
+		want   int
+	}{
+		{"disabled", config.MetricsConfig{}, "127.0.0.1:1234", "", http.StatusNotFound},
+		{"local without token", config.MetricsConfig{Pprof: true}, "127.0.0.1:1234", "", http.StatusOK},
+		{"remote without token", config.MetricsConfig{Pprof: true}, "192.0.2.1:1234", "", http.StatusForbidden},
+		{"wrong token", config.MetricsConfig{Pprof: true, PprofToken: "s3cret"}, "192.0.2.1:1234", "Bearer nope", http.StatusUnauthorized},
+		{"token", config.MetricsConfig{Pprof: true, PprofToken: "s3cret"}, "192.0.2.1:1234", "Bearer s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/debug/pprof/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			AdminHandler(tt.cfg, NewMetrics()).ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}`,
+
+	"internal/telemetry/tracing_test.go": `package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"{{.ProjectName}}/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_DisabledIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{}, "test")
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	_, span := otel.Tracer("test").Start(context.Background(), "work")
+	defer span.End()
+	assert.False(t, span.IsRecording())
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestTracing_ServiceSpansNestUnderRequest(t *testing.T) {
+	// Setup installs the propagator; spans go to a recorder instead of OTLP
+	_, err := Setup(context.Background(), config.TracingConfig{}, "test")
+	require.NoError(t, err)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(otelgin.Middleware("test"))
+	router.GET("/items/:id", func(c *gin.Context) {
+		// What a service method does with the request context
+		_, span := otel.Tracer("services").Start(c.Request.Context(), "ItemService.GetItem")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+
+	// An upstream caller's trace is continued, not replaced
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/items/1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	service, request := spans[0], spans[1]
+	assert.Equal(t, "ItemService.GetItem", service.Name())
+	assert.Equal(t, "GET /items/:id", request.Name())
+	assert.Equal(t, request.SpanContext().SpanID(), service.Parent().SpanID())
+	assert.Equal(t, traceID, request.SpanContext().TraceID().String())
+	assert.Equal(t, traceID, service.SpanContext().TraceID().String())
+}`,
+
+	"internal/jobs/worker_test.go": `package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore is an in-memory Store that reports what happened to each job.
+type fakeStore struct {
+	mu     sync.Mutex
+	queue  []*QueuedJob
+	done   chan *QueuedJob
+	failed chan error
+}
+
+func newFakeStore(jobs ...*QueuedJob) *fakeStore {
+	return &fakeStore{
+		queue:  jobs,
+		done:   make(chan *QueuedJob, len(jobs)),
+		failed: make(chan error, len(jobs)),
+	}
+}
+
+func (s *fakeStore) Reserve(ctx context.Context) (*QueuedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return nil, ErrNoJob
+	}
+	job := s.queue[0]
+	s.queue = s.queue[1:]
+	job.Attempts++
+	return job, nil
+}
+
+func (s *fakeStore) Complete(ctx context.Context, job *QueuedJob) error {
+	s.done <- job
+	return nil
+}
+
+func (s *fakeStore) Fail(ctx context.Context, job *QueuedJob, err error) error {
+	s.failed <- err
+	return nil
+}
+
+// jobFunc is a Job of type "test" running a function.
+type jobFunc func(ctx context.Context, payload json.RawMessage) error
+
+func (f jobFunc) Type() string { return "test" }
+func (f jobFunc) Handle(ctx context.Context, payload json.RawMessage) error {
+	return f(ctx, payload)
+}
+
+func testConfig() config.JobsConfig {
+	return config.JobsConfig{
+		Concurrency:  2,
+		PollInterval: 10 * time.Millisecond,
+		Timeout:      time.Second,
+		MaxAttempts:  3,
+		Backoff:      time.Second,
+		MaxBackoff:   time.Minute,
+	}
+}
+
+// runPool runs a pool over store until the test ends.
+func runPool(t *testing.T, store Store, job Job) {
+	registry, err := NewRegistry(job)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		NewPool(store, registry, testConfig()).Run(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+		var zero T
+		return zero
+	}
+}
+
+func TestPool_CompletesJobs(t *testing.T) {
+	store := newFakeStore(
+		&QueuedJob{ID: 1, Type: "test", Payload: ` + "`" + `"a"` + "`" + `},
+		&QueuedJob{ID: 2, Type: "test", Payload: ` + "`" + `"b"` + "`" + `},
+	)
+	var mu sync.Mutex
+	var payloads []string
+	runPool(t, store, jobFunc(func(ctx context.Context, payload json.RawMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		payloads = append(payloads, string(payload))
+		return nil
+	}))
+
+	receive(t, store.done)
+	receive(t, store.done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{` + "`" + `"a"` + "`" + `, ` + "`" + `"b"` + "`" + `}, payloads)
+}
+
+func TestPool_FailsJobs(t *testing.T) {
+	tests := map[string]struct {
+		job     jobFunc
+		jobType string
+		want    string
+	}{
+		"error": {
+			job:     func(ctx context.Context, payload json.RawMessage) error { return errors.New("boom") },
+			jobType: "test",
+			want:    "boom",
+		},
+		"panic": {
+			job:     func(ctx context.Context, payload json.RawMessage) error { panic("boom") },
+			jobType: "test",
+			want:    "panic: boom",
+		},
+		"unknown type": {
+			job:     func(ctx context.Context, payload json.RawMessage) error { return nil },
+			jobType: "unknown",
+			want:    ` + "`" + `no job registered for type "unknown"` + "`" + `,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := newFakeStore(&QueuedJob{ID: 1, Type: tt.jobType, Payload: ` + "`" + `{}` + "`" + `})
+			runPool(t, store, tt.job)
+
+			err := receive(t, store.failed)
+
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestPool_FinishesJobsInProgressOnShutdown(t *testing.T) {
+	store := newFakeStore(&QueuedJob{ID: 1, Type: "test", Payload: ` + "`" + `{}` + "`" + `})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	registry, err := NewRegistry(jobFunc(func(ctx context.Context, payload json.RawMessage) error {
+		close(started)
+		<-release
+		return ctx.Err()
+	}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		NewPool(store, registry, testConfig()).Run(ctx)
+		close(stopped)
+	}()
+
+	<-started
+	cancel()
+	select {
+	case <-stopped:
+		t.Fatal("Run returned before the job in progress finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	receive(t, stopped)
+	assert.Equal(t, 1, len(store.done), "the job should complete, not fail with a cancelled context")
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := testConfig()
+
+	for attempt, want := range map[int]time.Duration{
+		1:   time.Second,
+		2:   2 * time.Second,
+		3:   4 * time.Second,
+		7:   time.Minute,
+		100: time.Minute,
+	} {
+		got := Backoff(cfg, attempt)
+		assert.GreaterOrEqual(t, got, want, "attempt %d", attempt)
+		assert.LessOrEqual(t, got, want+want/10, "attempt %d", attempt)
+	}
+}
+
+func TestNewRegistry_RejectsDuplicateTypes(t *testing.T) {
+	job := jobFunc(func(ctx context.Context, payload json.RawMessage) error { return nil })
+
+	_, err := NewRegistry(job, job)
+
+	assert.EqualError(t, err, ` + "`" + `job type "test" is registered twice` + "`" + `)
+}
+
+func TestPermanent(t *testing.T) {
+	err := fmt.Errorf("sending: %w", Permanent(errors.New("bad address")))
+
+	assert.True(t, IsPermanent(err))
+	assert.False(t, IsPermanent(errors.New("timeout")))
+	assert.NoError(t, Permanent(nil))
+}`,
+
+	"internal/jobs/queue_test.go": `package jobs_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"{{.ProjectName}}/internal/config"
+	"{{.ProjectName}}/internal/database"
+	"{{.ProjectName}}/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newQueue returns a queue in an empty jobs table of the database the
+// environment points at, as in CI. Without one the test is skipped.
+func newQueue(t *testing.T) (*jobs.Queue, *gorm.DB) {
+	t.Helper()
+	if os.Getenv(config.EnvName("database.host")) == "" {
+		t.Skipf("set %s to run the queue tests against a database", config.EnvName("database.host"))
+	}
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	db, err := database.NewConnection(cfg)
+	require.NoError(t, err)
+	db.Logger = db.Logger.LogMode(logger.Silent)
+
+	require.NoError(t, database.Migrate(db))
+	require.NoError(t, db.Where("1 = 1").Delete(&jobs.QueuedJob{}).Error)
+
+	cfg.Jobs.MaxAttempts = 2
+	return jobs.NewQueue(db, cfg.Jobs), db
+}
+
+func TestQueue_ReserveAndComplete(t *testing.T) {
+	queue, db := newQueue(t)
+	ctx := context.Background()
+
+	enqueued, err := queue.EnqueueExample(ctx, jobs.ExamplePayload{Message: "hello"})
+	require.NoError(t, err)
+
+	job, err := queue.Reserve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, enqueued.ID, job.ID)
+	assert.Equal(t, jobs.StatusRunning, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.JSONEq(t, ` + "`" + `{"message":"hello"}` + "`" + `, job.Payload)
+
+	_, err = queue.Reserve(ctx)
+	assert.ErrorIs(t, err, jobs.ErrNoJob, "a running job can't be reserved twice")
+
+	require.NoError(t, queue.Complete(ctx, job))
+	var count int64
+	require.NoError(t, db.Model(&jobs.QueuedJob{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestQueue_DelayedJobsWait(t *testing.T) {
+	queue, _ := newQueue(t)
+	ctx := context.Background()
+
+	_, err := queue.EnqueueExample(ctx, jobs.ExamplePayload{Message: "later"}, jobs.WithDelay(time.Hour))
+	require.NoError(t, err)
+
+	_, err = queue.Reserve(ctx)
+	assert.ErrorIs(t, err, jobs.ErrNoJob)
+}
+
+func TestQueue_FailRetriesThenDeadLetters(t *testing.T) {
+	queue, db := newQueue(t)
+	ctx := context.Background()
+
+	enqueued, err := queue.EnqueueExample(ctx, jobs.ExamplePayload{Message: "flaky"})
+	require.NoError(t, err)
+
+	job, err := queue.Reserve(ctx)
+	require.NoError(t, err)
+	require.NoError(t, queue.Fail(ctx, job, errors.New("first")))
+
+	var stored jobs.QueuedJob
+	require.NoError(t, db.First(&stored, enqueued.ID).Error)
+	assert.Equal(t, jobs.StatusPending, stored.Status)
+	assert.Equal(t, "first", stored.LastError)
+	assert.True(t, stored.RunAt.After(time.Now()), "the retry should wait for the backoff")
+
+	// Skip the backoff
+	require.NoError(t, db.Model(&stored).Update("run_at", time.Now().Add(-time.Second)).Error)
+	job, err = queue.Reserve(ctx)
+	require.NoError(t, err)
+	require.NoError(t, queue.Fail(ctx, job, errors.New("second")))
+
+	dead, err := queue.Dead(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "second", dead[0].LastError)
+	assert.Equal(t, 2, dead[0].Attempts)
+
+	require.NoError(t, queue.Retry(ctx, enqueued.ID))
+	job, err = queue.Reserve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.Attempts)
+}
+
+func TestQueue_PermanentErrorsDeadLetterAtOnce(t *testing.T) {
+	queue, _ := newQueue(t)
+	ctx := context.Background()
+
+	_, err := queue.EnqueueExample(ctx, jobs.ExamplePayload{})
+	require.NoError(t, err)
+
+	job, err := queue.Reserve(ctx)
+	require.NoError(t, err)
+	require.NoError(t, queue.Fail(ctx, job, jobs.Permanent(errors.New("invalid"))))
+
+	dead, err := queue.Dead(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, dead, 1)
+}
+
+func TestQueue_ReserveHandsEachJobToOneWorker(t *testing.T) {
+	queue, _ := newQueue(t)
+	ctx := context.Background()
+
+	const count = 20
+	for range count {
+		_, err := queue.EnqueueExample(ctx, jobs.ExamplePayload{Message: "once"})
+		require.NoError(t, err)
+	}
+
+	var mu sync.Mutex
+	seen := map[uint]int{}
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				job, err := queue.Reserve(ctx)
+				if errors.Is(err, jobs.ErrNoJob) {
+					return
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+				mu.Lock()
+				seen[job.ID]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, count)
+	for id, n := range seen {
+		assert.Equal(t, 1, n, "job %d was reserved %d times", id, n)
+	}
+}`,
+
+	"internal/jobs/example_job_test.go": `package jobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExample_Handle(t *testing.T) {
+	err := NewExample().Handle(context.Background(), []byte(` + "`" + `{"message":"hello"}` + "`" + `))
+
+	assert.NoError(t, err)
+}
+
+func TestExample_Handle_RejectsInvalidPayload(t *testing.T) {
+	for _, payload := range []string{` + "`" + `{"message":` + "`" + `, ` + "`" + `{}` + "`" + `} {
+		err := NewExample().Handle(context.Background(), []byte(payload))
+
+		assert.True(t, IsPermanent(err), "payload %s", payload)
+	}
+}`,
+
+	"internal/cache/cache_test.go": `{{- $env := envPrefix .ProjectName -}}
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID   uint   ` + "`" + `json:"id"` + "`" + `
+	Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+// newRedisCache returns a cache backed by an in-process Redis.
+func newRedisCache(t *testing.T) (*Cache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewWithStore(NewRedisStore(client, "test:"), time.Minute), server
+}
+
+// stores runs a test against each store.
+func stores(t *testing.T, test func(t *testing.T, c *Cache)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewWithStore(NewMemoryStore(100), time.Minute))
+	})
+	t.Run("redis", func(t *testing.T) {
+		c, _ := newRedisCache(t)
+		test(t, c)
+	})
+}
+
+// countingLoad returns a load function for value and the number of calls
+// made to it.
+func countingLoad[T any](value T, err error) (func(context.Context) (T, error), *atomic.Int32) {
+	var calls atomic.Int32
+	return func(context.Context) (T, error) {
+		calls.Add(1)
+		return value, err
+	}, &calls
+}
+
+func TestGetOrLoad_CachesValue(t *testing.T) {
+	stores(t, func(t *testing.T, c *Cache) {
+		ctx := context.Background()
+		load, calls := countingLoad(&record{ID: 1, Name: "first"}, nil)
+
+		for range 3 {
+			got, err := GetOrLoad(ctx, c, "record:1", load)
+			require.NoError(t, err)
+			assert.Equal(t, &record{ID: 1, Name: "first"}, got)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestGetOrLoad_CachesMissingRecord(t *testing.T) {
+	stores(t, func(t *testing.T, c *Cache) {
+		ctx := context.Background()
+		load, calls := countingLoad[*record](nil, nil)
+
+		for range 2 {
+			got, err := GetOrLoad(ctx, c, "record:2", load)
+			require.NoError(t, err)
+			assert.Nil(t, got)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestGetOrLoad_DoesNotCacheErrors(t *testing.T) {
+	stores(t, func(t *testing.T, c *Cache) {
+		ctx := context.Background()
+		load, calls := countingLoad[*record](nil, errors.New("database down"))
+
+		for range 2 {
+			_, err := GetOrLoad(ctx, c, "record:3", load)
+			assert.EqualError(t, err, "database down")
+		}
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestGetOrLoad_LoadsOnceForConcurrentMisses(t *testing.T) {
+	stores(t, func(t *testing.T, c *Cache) {
+		ctx := context.Background()
+		release := make(chan struct{})
+		var calls atomic.Int32
+		load := func(context.Context) (*record, error) {
+			calls.Add(1)
+			<-release
+			return &record{ID: 4}, nil
+		}
+
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := GetOrLoad(ctx, c, "record:4", load)
+				assert.NoError(t, err)
+				assert.Equal(t, &record{ID: 4}, got)
+			}()
+		}
+		// Give the callers time to pile up behind the first load
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestGetOrLoad_CallerGivingUpDoesNotCancelLoad(t *testing.T) {
+	c := NewWithStore(NewMemoryStore(100), time.Minute)
+	release := make(chan struct{})
+	load := func(ctx context.Context) (*record, error) {
+		<-release
+		return &record{ID: 5}, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := GetOrLoad(ctx, c, "record:5", load)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		got, err := GetOrLoad(context.Background(), c, "record:5", failingLoad)
+		return err == nil && got != nil && got.ID == 5
+	}, time.Second, 10*time.Millisecond)
+}
+
+func failingLoad(context.Context) (*record, error) {
+	return nil, errors.New("should have been cached")
+}
+
+func TestCache_Delete(t *testing.T) {
+	stores(t, func(t *testing.T, c *Cache) {
+		ctx := context.Background()
+		load, calls := countingLoad(&record{ID: 6}, nil)
+
+		_, err := GetOrLoad(ctx, c, "record:6", load)
+		require.NoError(t, err)
+		require.NoError(t, c.Delete(ctx, "record:6", "record:missing"))
+		_, err = GetOrLoad(ctx, c, "record:6", load)
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestGetOrLoad_FallsBackToLoadWhenRedisIsDown(t *testing.T) {
+	c, server := newRedisCache(t)
+	server.Close()
+	load, calls := countingLoad(&record{ID: 7}, nil)
+
+	got, err := GetOrLoad(context.Background(), c, "record:7", load)
+
+	require.NoError(t, err)
+	assert.Equal(t, &record{ID: 7}, got)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Error(t, c.Delete(context.Background(), "record:7"))
+}
+
+func TestRedisStore_ExpiresEntries(t *testing.T) {
+	c, server := newRedisCache(t)
+	ctx := context.Background()
+	load, calls := countingLoad(&record{ID: 8}, nil)
+
+	_, err := GetOrLoad(ctx, c, "record:8", load)
+	require.NoError(t, err)
+	assert.True(t, server.Exists("test:record:8"))
+
+	server.FastForward(time.Minute)
+	_, err = GetOrLoad(ctx, c, "record:8", load)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCache_JitteredTTL(t *testing.T) {
+	c := NewWithStore(NewMemoryStore(1), 100*time.Second)
+
+	for range 100 {
+		ttl := c.jitteredTTL()
+		assert.LessOrEqual(t, ttl, 100*time.Second)
+		assert.GreaterOrEqual(t, ttl, 90*time.Second)
+	}
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute))
+	_, ok, _ := store.Get(ctx, "a")
+	require.True(t, ok)
+	require.NoError(t, store.Set(ctx, "c", []byte("3"), time.Minute))
+
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok, "b was used least recently")
+	_, ok, _ = store.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestMemoryStore_ExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+	now = now.Add(59 * time.Second)
+	_, ok, _ := store.Get(ctx, "a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok, _ = store.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestNew_RejectsUnknownDriver(t *testing.T) {
+	_, err := New(Config{Driver: "memcached", TTL: time.Minute})
+
+	assert.ErrorContains(t, err, "unknown cache driver")
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("APP_ENV", "")
+	t.Setenv("{{$env}}_CACHE_DRIVER", "redis")
+	t.Setenv("{{$env}}_CACHE_TTL", "30s")
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("hunter2\n"), 0o600))
+	t.Setenv("{{$env}}_CACHE_REDIS_PASSWORD_FILE", passwordFile)
+
+	cfg, err := LoadConfig()
+
+	require.NoError(t, err)
+	assert.Equal(t, "redis", cfg.Driver)
+	assert.Equal(t, 30*time.Second, cfg.TTL)
+	assert.Equal(t, "localhost:6379", cfg.Redis.Addr)
+	assert.Equal(t, "hunter2", cfg.Redis.Password.Value())
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("APP_ENV", "")
+	t.Setenv("{{$env}}_CACHE_DRIVER", "disk")
+	t.Setenv("{{$env}}_CACHE_TTL", "0s")
+
+	_, err := LoadConfig()
+
+	assert.ErrorContains(t, err, "cache.driver ({{$env}}_CACHE_DRIVER) must be memory or redis")
+	assert.ErrorContains(t, err, "cache.ttl ({{$env}}_CACHE_TTL) must be positive")
+}`,
+
 	"internal/services/example_service_test.go": `package services
 
 import (
+	"context"
 	"testing"
 
 	"{{.ProjectName}}/internal/models"
@@ -81,27 +1431,27 @@ type MockExampleRepository struct {
 	mock.Mock
 }
 
-func (m *MockExampleRepository) FindAll() ([]*models.Example, error) {
+func (m *MockExampleRepository) FindAll(ctx context.Context) ([]*models.Example, error) {
 	args := m.Called()
 	return args.Get(0).([]*models.Example), args.Error(1)
 }
 
-func (m *MockExampleRepository) FindByID(id uint) (*models.Example, error) {
+func (m *MockExampleRepository) FindByID(ctx context.Context, id uint) (*models.Example, error) {
 	args := m.Called(id)
 	return args.Get(0).(*models.Example), args.Error(1)
 }
 
-func (m *MockExampleRepository) Create(example *models.Example) (*models.Example, error) {
+func (m *MockExampleRepository) Create(ctx context.Context, example *models.Example) (*models.Example, error) {
 	args := m.Called(example)
 	return args.Get(0).(*models.Example), args.Error(1)
 }
 
-func (m *MockExampleRepository) Update(example *models.Example) (*models.Example, error) {
+func (m *MockExampleRepository) Update(ctx context.Context, example *models.Example) (*models.Example, error) {
 	args := m.Called(example)
 	return args.Get(0).(*models.Example), args.Error(1)
 }
 
-func (m *MockExampleRepository) Delete(id uint) error {
+func (m *MockExampleRepository) Delete(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -114,7 +1464,7 @@ func TestExampleService_GetExample(t *testing.T) {
 	service := NewExampleService(mockRepo)
 
 	// Test GetExample
-	result := service.GetExample()
+	result := service.GetExample(context.Background())
 
 	// Assertions
 	assert.NotNil(t, result)
@@ -137,7 +1487,7 @@ func TestExampleService_GetAllExamples(t *testing.T) {
 	service := NewExampleService(mockRepo)
 
 	// Test GetAllExamples
-	result, err := service.GetAllExamples()
+	result, err := service.GetAllExamples(context.Background())
 
 	// Assertions
 	assert.NoError(t, err)
@@ -0,0 +1,247 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adipras/lupettogo/internal/output"
+)
+
+// baseDir holds the pristine template output of the last generation or
+// upgrade, relative to the project root.
+const baseDir = ".lupettogo/base"
+
+type UpgradeOptions struct {
+	Dir    string
+	DryRun bool
+}
+
+// UpgradeReport lists what an upgrade did to each file, by category.
+type UpgradeReport struct {
+	FromVersion string   `json:"from_version"`
+	ToVersion   string   `json:"to_version"`
+	DryRun      bool     `json:"dry_run"`
+	Added       []string `json:"added"`
+	Updated     []string `json:"updated"`
+	Merged      []string `json:"merged"`
+	Conflicts   []string `json:"conflicts"`
+	Unchanged   []string `json:"unchanged"`
+	Skipped     []string `json:"skipped"`
+	Obsolete    []string `json:"obsolete"`
+}
+
+// UpgradeProject re-renders the project and its recorded modules and jobs
+// with the current templates and merges the result into the files on disk.
+func UpgradeProject(opts UpgradeOptions) (*UpgradeReport, error) {
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+
+	manifest, err := LoadManifest(opts.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no %s found: upgrade needs the manifest written by 'lupettogo init'", ManifestName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UpgradeReport{FromVersion: manifest.GeneratorVersion, ToVersion: Version, DryRun: opts.DryRun}
+
+	fsys, err := projectTemplateFS(opts.Dir, manifest)
+	if err != nil {
+		return nil, err
+	}
+	projectFiles, err := renderProjectTemplates(fsys, manifest.projectData())
+	if err != nil {
+		return nil, fmt.Errorf("failed to render templates: %w", err)
+	}
+
+	moduleFiles := make([]map[string][]byte, len(manifest.Modules))
+	for i, module := range manifest.Modules {
+		files, err := renderModuleFiles(fsys, manifest.moduleData(module), manifest.Layout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render module %s: %w", module.Name, err)
+		}
+		moduleFiles[i] = files
+	}
+
+	jobFiles := make([]map[string][]byte, len(manifest.Jobs))
+	for i, job := range manifest.Jobs {
+		files, err := renderJobFiles(fsys, manifest.jobData(job))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render job %s: %w", job.Name, err)
+		}
+		jobFiles[i] = files
+	}
+
+	labels := mergeLabels{
+		Ours:   "yours",
+		Base:   "lupettogo " + report.FromVersion,
+		Theirs: "lupettogo " + report.ToVersion,
+	}
+
+	rendered := map[string][]byte{}
+	for path, content := range projectFiles {
+		rendered[path] = content
+	}
+	for _, files := range append(moduleFiles, jobFiles...) {
+		for path, content := range files {
+			rendered[path] = content
+		}
+	}
+
+	for _, path := range sortedPaths(rendered) {
+		if err := upgradeFile(opts, manifest, path, rendered[path], labels, report); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range manifest.Files {
+		if _, ok := projectFiles[filepath.FromSlash(file.Path)]; !ok {
+			report.Obsolete = append(report.Obsolete, file.Path)
+		}
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	// The freshly rendered output becomes the base for the next upgrade
+	if err := saveBaseSnapshot(opts.Dir, rendered); err != nil {
+		return nil, err
+	}
+
+	manifest.GeneratorVersion = Version
+	manifest.Files = manifestFiles(projectFiles)
+	for i := range manifest.Modules {
+		manifest.Modules[i].Files = manifestFiles(moduleFiles[i])
+	}
+	for i := range manifest.Jobs {
+		manifest.Jobs[i].Files = manifestFiles(jobFiles[i])
+	}
+	if err := manifest.Save(opts.Dir); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", ManifestName, err)
+	}
+
+	return report, nil
+}
+
+func upgradeFile(opts UpgradeOptions, manifest *Manifest, path string, theirs []byte, labels mergeLabels, report *UpgradeReport) error {
+	target := filepath.Join(opts.Dir, path)
+	recorded, tracked := manifest.checksumOf(path)
+
+	ours, err := os.ReadFile(target)
+	if errors.Is(err, os.ErrNotExist) {
+		if tracked {
+			// Generated before and deleted since: respect the deletion
+			report.Skipped = append(report.Skipped, path)
+			return nil
+		}
+		report.Added = append(report.Added, path)
+		return writeUpgradedFile(opts, target, theirs)
+	}
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(ours, theirs) {
+		report.Unchanged = append(report.Unchanged, path)
+		return nil
+	}
+
+	base, err := os.ReadFile(filepath.Join(opts.Dir, baseDir, path))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		// Without a snapshot the recorded checksum tells us whether the file
+		// is still exactly what the generator wrote.
+		if tracked && checksum(ours) == recorded {
+			base = ours
+		} else {
+			base = nil
+		}
+	}
+
+	if bytes.Equal(ours, base) {
+		report.Updated = append(report.Updated, path)
+		return writeUpgradedFile(opts, target, theirs)
+	}
+	if bytes.Equal(theirs, base) {
+		// Only the user changed the file
+		report.Unchanged = append(report.Unchanged, path)
+		return nil
+	}
+
+	merged, conflict := merge3(string(base), string(ours), string(theirs), labels)
+	if conflict {
+		report.Conflicts = append(report.Conflicts, path)
+	} else {
+		report.Merged = append(report.Merged, path)
+	}
+	return writeUpgradedFile(opts, target, []byte(merged))
+}
+
+func writeUpgradedFile(opts UpgradeOptions, target string, content []byte) error {
+	if opts.DryRun {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(target, content, 0644)
+}
+
+// PrintUpgradeReport prints a human readable summary of an upgrade and
+// records it for JSON output.
+func PrintUpgradeReport(report *UpgradeReport, dryRun bool) {
+	output.Set("upgrade", report)
+	if !dryRun {
+		output.Created(report.Added...)
+		output.Updated(report.Updated...)
+		output.Updated(report.Merged...)
+		output.Updated(report.Conflicts...)
+		for _, path := range report.Skipped {
+			output.Skipped(path, "deleted locally")
+		}
+	}
+	for _, path := range report.Conflicts {
+		output.Warnf("%s has merge conflicts", path)
+	}
+
+	if dryRun {
+		output.Printf("🔍 Dry run: upgrade from %s to %s\n", report.FromVersion, report.ToVersion)
+	} else {
+		output.Printf("⬆️  Upgraded from %s to %s\n", report.FromVersion, report.ToVersion)
+	}
+
+	sections := []struct {
+		icon  string
+		label string
+		paths []string
+	}{
+		{"➕", "Added", report.Added},
+		{"✅", "Updated", report.Updated},
+		{"🔀", "Merged", report.Merged},
+		{"⚠️ ", "Conflicts", report.Conflicts},
+		{"⏭️ ", "Skipped (deleted locally)", report.Skipped},
+		{"🗑️ ", "No longer generated", report.Obsolete},
+	}
+	for _, section := range sections {
+		if len(section.paths) == 0 {
+			continue
+		}
+		output.Printf("%s %s (%d):\n", section.icon, section.label, len(section.paths))
+		for _, path := range section.paths {
+			output.Printf("   - %s\n", path)
+		}
+	}
+	output.Printf("   %d file(s) unchanged\n", len(report.Unchanged))
+
+	if len(report.Conflicts) > 0 {
+		output.Printf("📝 Resolve the conflict markers (<<<<<<< yours ... >>>>>>> lupettogo %s) before building\n", report.ToVersion)
+	}
+}
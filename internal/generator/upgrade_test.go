@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUpgradeFile(t *testing.T) {
+	const path = "internal/app.go"
+	generated := "a\nb\nc\n"
+
+	tests := []struct {
+		name string
+		// ours is the file on disk; nil means the user deleted it
+		ours *string
+		// base is the snapshot; nil means there is none
+		base    *string
+		tracked bool
+		// recorded overrides the checksum of base in the manifest
+		recorded string
+		theirs   string
+		dryRun   bool
+
+		wantCategory string
+		// wantContent nil means the file must not exist
+		wantContent *string
+	}{
+		{
+			name: "untouched file is updated",
+			ours: ptr(generated), base: ptr(generated), tracked: true,
+			theirs:       "a\nB\nc\n",
+			wantCategory: "updated", wantContent: ptr("a\nB\nc\n"),
+		},
+		{
+			name: "identical file is unchanged",
+			ours: ptr("a\nB\nc\n"), base: ptr(generated), tracked: true,
+			theirs:       "a\nB\nc\n",
+			wantCategory: "unchanged", wantContent: ptr("a\nB\nc\n"),
+		},
+		{
+			name: "user-only change is kept",
+			ours: ptr("a\nmine\nc\n"), base: ptr(generated), tracked: true,
+			theirs:       generated,
+			wantCategory: "unchanged", wantContent: ptr("a\nmine\nc\n"),
+		},
+		{
+			name: "non-overlapping changes are merged",
+			ours: ptr("mine\nb\nc\n"), base: ptr(generated), tracked: true,
+			theirs:       "a\nb\nnew\n",
+			wantCategory: "merged", wantContent: ptr("mine\nb\nnew\n"),
+		},
+		{
+			name: "overlapping changes conflict",
+			ours: ptr("a\nmine\nc\n"), base: ptr(generated), tracked: true,
+			theirs:       "a\nnew\nc\n",
+			wantCategory: "conflicts",
+			wantContent:  ptr("a\n<<<<<<< yours\nmine\n||||||| base\nb\n=======\nnew\n>>>>>>> theirs\nc\n"),
+		},
+		{
+			name: "deleted tracked file stays deleted",
+			base: ptr(generated), tracked: true,
+			theirs:       "a\nB\nc\n",
+			wantCategory: "skipped",
+		},
+		{
+			name:         "new template file is added",
+			theirs:       generated,
+			wantCategory: "added", wantContent: ptr(generated),
+		},
+		{
+			name: "no snapshot, checksum matches",
+			ours: ptr(generated), tracked: true, recorded: checksum([]byte(generated)),
+			theirs:       "a\nB\nc\n",
+			wantCategory: "updated", wantContent: ptr("a\nB\nc\n"),
+		},
+		{
+			name: "no snapshot, checksum differs",
+			ours: ptr("a\nmine\nc\n"), tracked: true, recorded: checksum([]byte(generated)),
+			theirs:       "a\nB\nc\n",
+			wantCategory: "conflicts",
+			wantContent:  ptr("<<<<<<< yours\na\nmine\nc\n||||||| base\n=======\na\nB\nc\n>>>>>>> theirs\n"),
+		},
+		{
+			name: "dry run writes nothing",
+			ours: ptr("mine\nb\nc\n"), base: ptr(generated), tracked: true,
+			theirs: "a\nb\nnew\n", dryRun: true,
+			wantCategory: "merged", wantContent: ptr("mine\nb\nc\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{}
+			if tt.ours != nil {
+				files[path] = *tt.ours
+			}
+			if tt.base != nil {
+				files[filepath.ToSlash(filepath.Join(baseDir, path))] = *tt.base
+			}
+			writeTree(t, dir, files)
+
+			manifest := &Manifest{}
+			if tt.tracked {
+				recorded := tt.recorded
+				if recorded == "" && tt.base != nil {
+					recorded = checksum([]byte(*tt.base))
+				}
+				manifest.Files = []ManifestFile{{Path: path, Checksum: recorded}}
+			}
+
+			report := &UpgradeReport{}
+			opts := UpgradeOptions{Dir: dir, DryRun: tt.dryRun}
+			if err := upgradeFile(opts, manifest, filepath.FromSlash(path), []byte(tt.theirs), testLabels, report); err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string][]string{tt.wantCategory: {filepath.FromSlash(path)}}
+			if got := reportCategories(report); !reflect.DeepEqual(got, want) {
+				t.Errorf("report = %v, want %v", got, want)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, path))
+			switch {
+			case tt.wantContent == nil && !os.IsNotExist(err):
+				t.Errorf("%s exists, want it deleted", path)
+			case tt.wantContent != nil && string(content) != *tt.wantContent:
+				t.Errorf("%s = %q, want %q", path, content, *tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestUpgradeProjectKeepsUserChanges(t *testing.T) {
+	work := sandbox(t)
+	if err := GenerateProject("myapp"); err != nil {
+		t.Fatal(err)
+	}
+	project := filepath.Join(work, "myapp")
+
+	readme := filepath.Join(project, "README.md")
+	edited := readFile(t, readme) + "\nLocal notes.\n"
+	if err := os.WriteFile(readme, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	makefile := filepath.Join(project, "Makefile")
+	if err := os.Remove(makefile); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := UpgradeProject(UpgradeOptions{Dir: project})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Conflicts) != 0 || len(report.Merged) != 0 || len(report.Added) != 0 {
+		t.Errorf("upgrading with the same templates changed files: %+v", report)
+	}
+	if !reflect.DeepEqual(report.Skipped, []string{"Makefile"}) {
+		t.Errorf("skipped = %v, want the deleted Makefile", report.Skipped)
+	}
+	if readFile(t, readme) != edited {
+		t.Error("README.md lost the user's change")
+	}
+	if _, err := os.Stat(makefile); !os.IsNotExist(err) {
+		t.Error("the deleted Makefile was recreated")
+	}
+}
+
+func reportCategories(report *UpgradeReport) map[string][]string {
+	categories := map[string][]string{}
+	for name, paths := range map[string][]string{
+		"added":     report.Added,
+		"updated":   report.Updated,
+		"merged":    report.Merged,
+		"conflicts": report.Conflicts,
+		"unchanged": report.Unchanged,
+		"skipped":   report.Skipped,
+	} {
+		if len(paths) > 0 {
+			categories[name] = paths
+		}
+	}
+	return categories
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,171 @@
+// Package output routes what commands report to the user. In text mode it
+// prints the usual human readable lines; with --output json it collects the
+// results into a single JSON document written when the command finishes, and
+// with --quiet it prints nothing but warnings and errors.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"golang.org/x/term"
+)
+
+// Output formats accepted by --output.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Result is the document written in JSON mode.
+type Result struct {
+	Command  string         `json:"command"`
+	Success  bool           `json:"success"`
+	Error    string         `json:"error,omitempty"`
+	Created  []string       `json:"created"`
+	Updated  []string       `json:"updated"`
+	Skipped  []SkippedFile  `json:"skipped"`
+	Warnings []string       `json:"warnings"`
+	Data     map[string]any `json:"data,omitempty"`
+}
+
+// SkippedFile is a file a command chose not to write.
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+var (
+	format         = FormatText
+	quiet          bool
+	nonInteractive bool
+
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+
+	result = Result{Data: map[string]any{}}
+)
+
+// Configure sets the output mode from the global flags.
+func Configure(outputFormat string, quietMode, noInput bool) error {
+	switch outputFormat {
+	case FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("unsupported output format %q (supported: %s, %s)", outputFormat, FormatText, FormatJSON)
+	}
+	format = outputFormat
+	quiet = quietMode
+	nonInteractive = noInput
+	return nil
+}
+
+// JSON reports whether results are collected as JSON.
+func JSON() bool {
+	return format == FormatJSON
+}
+
+// human reports whether informational text is printed.
+func human() bool {
+	return format == FormatText && !quiet
+}
+
+// Interactive reports whether commands may prompt on stdin: only in text
+// mode, without --non-interactive, and when stdin is a terminal.
+func Interactive() bool {
+	if !human() || nonInteractive {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// Printf prints informational text in text mode.
+func Printf(format string, args ...any) {
+	if human() {
+		fmt.Fprintf(stdout, format, args...)
+	}
+}
+
+// Println prints an informational line in text mode.
+func Println(args ...any) {
+	if human() {
+		fmt.Fprintln(stdout, args...)
+	}
+}
+
+// Warnf reports a warning. It is printed in text mode, to stderr when
+// quiet, and recorded in JSON mode.
+func Warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	switch {
+	case JSON():
+		result.Warnings = append(result.Warnings, msg)
+	case quiet:
+		fmt.Fprintf(stderr, "⚠️  %s\n", msg)
+	default:
+		fmt.Fprintf(stdout, "⚠️  %s\n", msg)
+	}
+}
+
+// Stream returns where subprocess output should go: the terminal in text
+// mode, stderr in JSON mode so stdout stays a single document, and nowhere
+// when quiet.
+func Stream() io.Writer {
+	switch {
+	case JSON():
+		return stderr
+	case quiet:
+		return io.Discard
+	}
+	return stdout
+}
+
+// Created records files a command wrote for the first time.
+func Created(paths ...string) {
+	result.Created = append(result.Created, paths...)
+}
+
+// Updated records existing files a command changed.
+func Updated(paths ...string) {
+	result.Updated = append(result.Updated, paths...)
+}
+
+// Skipped records a file a command didn't write and why.
+func Skipped(path, reason string) {
+	result.Skipped = append(result.Skipped, SkippedFile{Path: path, Reason: reason})
+}
+
+// Set records command specific data under key.
+func Set(key string, value any) {
+	result.Data[key] = value
+}
+
+// Finish writes the JSON document for command in JSON mode. err is the
+// error the command failed with, if any.
+func Finish(command string, err error) error {
+	if !JSON() {
+		return nil
+	}
+
+	result.Command = command
+	result.Success = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+	for _, list := range []*[]string{&result.Created, &result.Updated, &result.Warnings} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	sort.Strings(result.Created)
+	sort.Strings(result.Updated)
+	if result.Skipped == nil {
+		result.Skipped = []SkippedFile{}
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
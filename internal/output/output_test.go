@@ -0,0 +1,187 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// capture configures the package for a test and returns what it writes to
+// stdout and stderr.
+func capture(t *testing.T, outputFormat string, quietMode, noInput bool) (*bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	savedStdout, savedStderr := stdout, stderr
+	t.Cleanup(func() {
+		format, quiet, nonInteractive = FormatText, false, false
+		stdout, stderr = savedStdout, savedStderr
+		result = Result{Data: map[string]any{}}
+	})
+
+	if err := Configure(outputFormat, quietMode, noInput); err != nil {
+		t.Fatal(err)
+	}
+	var out, errOut bytes.Buffer
+	stdout, stderr = &out, &errOut
+	result = Result{Data: map[string]any{}}
+	return &out, &errOut
+}
+
+func TestConfigureRejectsUnknownFormat(t *testing.T) {
+	capture(t, FormatText, false, false)
+	if err := Configure("yaml", false, false); err == nil {
+		t.Fatal("Configure accepted the yaml format")
+	}
+	if JSON() {
+		t.Error("a rejected format changed the output mode")
+	}
+}
+
+func TestFinishJSONDocument(t *testing.T) {
+	out, errOut := capture(t, FormatJSON, false, false)
+	Printf("not in the document\n")
+	Created("b.go", "a.go")
+	Updated("z.go", "y.go")
+	Warnf("careful with %s", "x")
+	Set("module", "product")
+
+	if err := Finish("generate module", nil); err != nil {
+		t.Fatal(err)
+	}
+	if errOut.Len() != 0 {
+		t.Errorf("stderr = %q, want nothing", errOut)
+	}
+
+	var doc Result
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("stdout is not one JSON document: %v\n%s", err, out)
+	}
+	want := Result{
+		Command:  "generate module",
+		Success:  true,
+		Created:  []string{"a.go", "b.go"},
+		Updated:  []string{"y.go", "z.go"},
+		Skipped:  []SkippedFile{},
+		Warnings: []string{"careful with x"},
+		Data:     map[string]any{"module": "product"},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("document = %+v, want %+v", doc, want)
+	}
+}
+
+func TestFinishJSONEmptyAndFailed(t *testing.T) {
+	out, _ := capture(t, FormatJSON, false, false)
+	if err := Finish("init", errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("stdout is not one JSON document: %v\n%s", err, out)
+	}
+	if doc["success"] != false || doc["error"] != "boom" {
+		t.Errorf("success = %v, error = %v; want false and the command's error", doc["success"], doc["error"])
+	}
+	// Lists are always present, empty rather than null
+	for _, key := range []string{"created", "updated", "skipped", "warnings"} {
+		if list, ok := doc[key].([]any); !ok || len(list) != 0 {
+			t.Errorf("%s = %#v, want an empty list", key, doc[key])
+		}
+	}
+	if _, ok := doc["data"]; ok {
+		t.Error("empty data was written")
+	}
+}
+
+func TestFinishTextWritesNothing(t *testing.T) {
+	out, _ := capture(t, FormatText, false, false)
+	Created("a.go")
+	if err := Finish("init", nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("text mode wrote %q", out)
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	out, errOut := capture(t, FormatText, true, false)
+	Printf("created %s\n", "a.go")
+	Println("done")
+	Warnf("careful")
+
+	if out.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing when quiet", out)
+	}
+	if got := errOut.String(); got != "⚠️  careful\n" {
+		t.Errorf("stderr = %q, want the warning", got)
+	}
+}
+
+func TestTextWarningsGoToStdout(t *testing.T) {
+	out, errOut := capture(t, FormatText, false, false)
+	Println("done")
+	Warnf("careful")
+	if got := out.String(); got != "done\n⚠️  careful\n" {
+		t.Errorf("stdout = %q", got)
+	}
+	if errOut.Len() != 0 {
+		t.Errorf("stderr = %q, want nothing", errOut)
+	}
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		quiet  bool
+		want   string
+	}{
+		{name: "text", format: FormatText, want: "stdout"},
+		{name: "json", format: FormatJSON, want: "stderr"},
+		{name: "json and quiet", format: FormatJSON, quiet: true, want: "stderr"},
+		{name: "quiet", format: FormatText, quiet: true, want: "discard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut := capture(t, tt.format, tt.quiet, false)
+			got := "discard"
+			switch Stream() {
+			case io.Writer(out):
+				got = "stdout"
+			case io.Writer(errOut):
+				got = "stderr"
+			case io.Discard:
+			default:
+				t.Fatalf("Stream() = %T", Stream())
+			}
+			if got != tt.want {
+				t.Errorf("Stream() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInteractive(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		quiet   bool
+		noInput bool
+	}{
+		{name: "non-interactive", format: FormatText, noInput: true},
+		{name: "json", format: FormatJSON},
+		{name: "quiet", format: FormatText, quiet: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture(t, tt.format, tt.quiet, tt.noInput)
+			if Interactive() {
+				t.Error("Interactive() = true, want prompts disabled")
+			}
+		})
+	}
+}